@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrHandlerClosed, returned when writing to a handler after it's closed.
+var ErrHandlerClosed = errors.New("logging: handler closed")
+
+// logging.OverflowPolicy, what AsyncHandler does with a record when its queue is full.
+type OverflowPolicy int
+
+const (
+	// Block, the caller waits until there's room, no record is ever lost, for batch jobs.
+	Block OverflowPolicy = iota
+	// DropNewest, the new record is dropped, the caller never waits.
+	DropNewest
+	// DropOldest, the oldest queued record is dropped to make room for the new one, the
+	// caller never waits and the most recent records are kept.
+	DropOldest
+)
+
+// logging.AsyncHandler, wrap Handler and write records to it on a background
+// goroutine, so the caller doesn't wait for slow destinations. Records are
+// queued in a buffer of QueueSize; when it's full, OverflowPolicy applies,
+// Dropped counts the records it dropped.
+type AsyncHandler struct {
+	Handler        MessageHandler
+	QueueSize      int            // Default: 1024
+	OverflowPolicy OverflowPolicy // Default: Block
+
+	dropped     atomic.Uint64
+	once        sync.Once
+	mutex       sync.RWMutex
+	closed      bool
+	queue       chan asyncItem
+	done        chan struct{}
+	handleMutex sync.Mutex // guard Handler against configuration changes while it's writing.
+}
+
+type asyncItem struct {
+	record       MessageRecord
+	errorHandler func(err error)
+	formatter    Formatter
+	flushed      chan struct{}
+}
+
+// logging.AsyncHandler.Handle, queue a copy of the record of logger.
+func (handler *AsyncHandler) Handle(logger *Logger) error {
+	handler.once.Do(handler.start)
+
+	handler.mutex.RLock()
+	defer handler.mutex.RUnlock()
+
+	if handler.closed {
+		return ErrHandlerClosed
+	}
+	item := asyncItem{record: *logger.Record, errorHandler: logger.ErrorHandler, formatter: logger.DefaultFormatter}
+	switch handler.OverflowPolicy {
+	case DropNewest:
+		select {
+		case handler.queue <- item:
+		default:
+			handler.dropped.Add(1)
+			logger.counters().queue.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case handler.queue <- item:
+				return nil
+			default:
+			}
+			select {
+			case oldest := <-handler.queue:
+				if oldest.flushed != nil {
+					// a Flush is waiting for it, it's not a record.
+					close(oldest.flushed)
+				} else {
+					handler.dropped.Add(1)
+					logger.counters().queue.Add(1)
+				}
+			default:
+			}
+		}
+	default:
+		handler.queue <- item
+	}
+	return nil
+}
+
+// logging.AsyncHandler.Dropped, return how many records have been dropped because the queue was full.
+func (handler *AsyncHandler) Dropped() uint64 {
+	return handler.dropped.Load()
+}
+
+// logging.AsyncHandler.SetLevel, set the level of Handler if it's a LevelSetter.
+func (handler *AsyncHandler) SetLevel(level MessageLevel) {
+	if setter, ok := handler.Handler.(LevelSetter); ok {
+		handler.handleMutex.Lock()
+		setter.SetLevel(level)
+		handler.handleMutex.Unlock()
+	}
+}
+
+// logging.AsyncHandler.Flush, wait until every record queued before is written, then flush Handler.
+func (handler *AsyncHandler) Flush() error {
+	handler.once.Do(handler.start)
+
+	handler.mutex.RLock()
+	if handler.closed {
+		handler.mutex.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	handler.queue <- asyncItem{flushed: flushed}
+	handler.mutex.RUnlock()
+	<-flushed
+	if flusher, ok := handler.Handler.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// logging.AsyncHandler.Reopen, reopen Handler if it's a Reopener, between two queued records, the
+// next ones are written to the reopened files.
+func (handler *AsyncHandler) Reopen() error {
+	if reopener, ok := handler.Handler.(Reopener); ok {
+		handler.handleMutex.Lock()
+		defer handler.handleMutex.Unlock()
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+// logging.AsyncHandler.Close, stop accepting records, wait until the queued ones are written,
+// then close Handler.
+func (handler *AsyncHandler) Close() error {
+	handler.once.Do(handler.start)
+
+	handler.mutex.Lock()
+	if handler.closed {
+		handler.mutex.Unlock()
+		return nil
+	}
+	handler.closed = true
+	close(handler.queue)
+	handler.mutex.Unlock()
+	<-handler.done
+	return closeHandler(handler.Handler)
+}
+
+func (handler *AsyncHandler) start() {
+	size := handler.QueueSize
+	if size <= 0 {
+		size = 1024
+	}
+	handler.queue = make(chan asyncItem, size)
+	handler.done = make(chan struct{})
+	go handler.drain()
+}
+
+// drain, replay every queued record to Handler through a private logger.
+func (handler *AsyncHandler) drain() {
+	defer close(handler.done)
+
+	logger := &Logger{}
+	for item := range handler.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		logger.ErrorHandler = item.errorHandler
+		logger.DefaultFormatter = item.formatter
+		handler.handleMutex.Lock()
+		err := handleRecord(handler.Handler, logger, &item.record)
+		handler.handleMutex.Unlock()
+		if err != nil {
+			logger.handleError(err)
+		}
+	}
+}
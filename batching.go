@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+)
+
+// logging.MessageWriter, write formatted messages, like FileMessageHandler and every other
+// handler of this package writing to a destination.
+type MessageWriter interface {
+	Write(message []byte) error
+}
+
+// logging.BatchingHandler, format every record as it arrives, but write them to Target
+// in batches, at most BatchSize messages joined by newlines in a single Write, to cut
+// the write syscalls of high-volume logs. A batch is written when it's full, every
+// FlushInterval, and on Flush and Close.
+type BatchingHandler struct {
+	Level         MessageLevel
+	Filter        MessageFilter
+	Formatter     Formatter
+	Target        MessageWriter
+	BatchSize     int           // Default: 100
+	FlushInterval time.Duration // Default: 1 second
+
+	mutex  sync.Mutex
+	batch  bytes.Buffer
+	count  int
+	stop   chan struct{}
+	closed bool
+}
+
+// logging.BatchingHandler.Handle, add the record of logger to the batch if it passes level and filter.
+func (handler *BatchingHandler) Handle(logger *Logger) error {
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, handler.Write)
+}
+
+// logging.BatchingHandler.SetLevel, set the minimum level of records to write.
+func (handler *BatchingHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.BatchingHandler.Write, add message to the batch, write the batch if it's full.
+func (handler *BatchingHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.closed {
+		return ErrHandlerClosed
+	}
+	if handler.stop == nil {
+		interval := handler.FlushInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		handler.stop = make(chan struct{})
+		go handler.autoFlush(interval, handler.stop)
+	}
+	handler.batch.Write(message)
+	if len(message) == 0 || message[len(message)-1] != '\n' {
+		handler.batch.WriteByte('\n')
+	}
+	handler.count++
+
+	size := handler.BatchSize
+	if size <= 0 {
+		size = 100
+	}
+	if handler.count >= size {
+		return handler.flush()
+	}
+	return nil
+}
+
+// logging.BatchingHandler.Flush, write the batch to Target, then flush it if it's a Flusher.
+func (handler *BatchingHandler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.flush()
+	if flusher, ok := handler.Target.(Flusher); ok {
+		err = errors.Join(err, flusher.Flush())
+	}
+	return err
+}
+
+// logging.BatchingHandler.Reopen, write the batch to Target, then reopen it if it's a Reopener,
+// so the batch goes to the file it was made for.
+func (handler *BatchingHandler) Reopen() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.flush()
+	if reopener, ok := handler.Target.(Reopener); ok {
+		err = errors.Join(err, reopener.Reopen())
+	}
+	return err
+}
+
+// logging.BatchingHandler.Close, write the batch to Target, then close it if it's a Closer.
+func (handler *BatchingHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.closed {
+		return nil
+	}
+	handler.closed = true
+	if handler.stop != nil {
+		close(handler.stop)
+	}
+	err := handler.flush()
+	if closer, ok := handler.Target.(Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+func (handler *BatchingHandler) flush() error {
+	if handler.count == 0 {
+		return nil
+	}
+	err := handler.Target.Write(handler.batch.Bytes())
+	handler.batch.Reset()
+	handler.count = 0
+	return err
+}
+
+// autoFlush, write the batch every interval until stop is closed.
+func (handler *BatchingHandler) autoFlush(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			handler.mutex.Lock()
+			handler.flush()
+			handler.mutex.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
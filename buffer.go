@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// logging.BufferFormatter, a Formatter which also writes the record of logger to an empty
+// buffer, handlers then write the bytes of a pooled buffer, without a string per record
+// and its copy to []byte. MessageFormatter, JSONFormatter, etc. are ones.
+type BufferFormatter interface {
+	Formatter
+	WriteMessage(logger *Logger, buffer *bytes.Buffer)
+}
+
+var (
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+	maxPooledBuffer atomic.Int64 // 0 means 64 KiB.
+)
+
+// logging.SetMaxPooledBufferSize, set the capacity above which a buffer of formatted messages
+// isn't kept for the next records, so a few huge messages don't keep their memory, 0 or less
+// restores the default. Default: 64 KiB
+func SetMaxPooledBufferSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	maxPooledBuffer.Store(int64(size))
+}
+
+// getBuffer, return an empty buffer of the pool.
+func getBuffer() *bytes.Buffer {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+// putBuffer, put buffer back to the pool unless it's bigger than SetMaxPooledBufferSize,
+// its bytes must not be used after.
+func putBuffer(buffer *bytes.Buffer) {
+	max := maxPooledBuffer.Load()
+	if max == 0 {
+		max = 64 << 10
+	}
+	if int64(buffer.Cap()) <= max {
+		bufferPool.Put(buffer)
+	}
+}
+
+// formatString, return the record of logger formatted by formatter, for GetMessage.
+func formatString(formatter BufferFormatter, logger *Logger) string {
+	buffer := getBuffer()
+	formatter.WriteMessage(logger, buffer)
+	message := buffer.String()
+	putBuffer(buffer)
+	return message
+}
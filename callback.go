@@ -0,0 +1,30 @@
+package logging
+
+// logging.CallbackHandler, pass every formatted record to Callback, with its level and the
+// record itself, to route records in code without implementing MessageHandler. formatted
+// and record are only valid during the call, Callback must copy them to keep them.
+type CallbackHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+	Callback  func(level MessageLevel, formatted []byte, record *MessageRecord)
+}
+
+// logging.NewCallbackHandler, return a handler passing every record to callback.
+func NewCallbackHandler(callback func(level MessageLevel, formatted []byte, record *MessageRecord)) *CallbackHandler {
+	return &CallbackHandler{Callback: callback}
+}
+
+// logging.CallbackHandler.Handle, pass the record of logger to Callback if it passes level and filter.
+func (handler *CallbackHandler) Handle(logger *Logger) error {
+	record := logger.Record
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, func(formatted []byte) error {
+		handler.Callback(record.Level, formatted, record)
+		return nil
+	})
+}
+
+// logging.CallbackHandler.SetLevel, set the minimum level of records to pass.
+func (handler *CallbackHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// logging.CircularBufferHandler, keep the last Size bytes of formatted messages in memory,
+// a new message overwrites the oldest ones, for a crash dump or an admin endpoint, see Dump.
+// Nothing is written anywhere until Dump is called.
+type CircularBufferHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+	Size      int // Default: 1 MiB
+
+	mutex   sync.Mutex
+	buffer  []byte
+	next    int  // where the next byte goes.
+	full    bool // the buffer has wrapped, the oldest byte is at next.
+	aligned bool // the oldest byte starts a message.
+}
+
+// logging.CircularBufferHandler.Handle, keep the record of logger if it passes level and filter.
+func (handler *CircularBufferHandler) Handle(logger *Logger) error {
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, handler.Write)
+}
+
+// logging.CircularBufferHandler.SetLevel, set the minimum level of records to keep.
+func (handler *CircularBufferHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.CircularBufferHandler.Write, keep message, followed by a newline if it doesn't end with one.
+func (handler *CircularBufferHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.write(message)
+	if len(message) == 0 || message[len(message)-1] != '\n' {
+		handler.write([]byte{'\n'})
+	}
+	return nil
+}
+
+func (handler *CircularBufferHandler) write(data []byte) {
+	if handler.buffer == nil {
+		size := handler.Size
+		if size <= 0 {
+			size = 1 << 20
+		}
+		handler.buffer = make([]byte, size)
+	}
+	size := len(handler.buffer)
+	if len(data) >= size {
+		// keep the end of a message bigger than the buffer.
+		copy(handler.buffer, data[len(data)-size:])
+		handler.next, handler.full, handler.aligned = 0, true, true
+		return
+	}
+	next := (handler.next + len(data)) % size
+	if handler.full || handler.next+len(data) >= size {
+		// the oldest byte will be at next, it starts a message if the last one overwritten ends one.
+		handler.aligned = (!handler.full && next == 0) || handler.buffer[(next+size-1)%size] == '\n'
+		handler.full = true
+	}
+	n := copy(handler.buffer[handler.next:], data)
+	copy(handler.buffer, data[n:])
+	handler.next = next
+}
+
+// logging.CircularBufferHandler.Bytes, return a copy of the kept messages, oldest first, without
+// the part of the oldest one which has been overwritten.
+func (handler *CircularBufferHandler) Bytes() []byte {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if !handler.full {
+		return append([]byte(nil), handler.buffer[:handler.next]...)
+	}
+	data := make([]byte, 0, len(handler.buffer))
+	data = append(data, handler.buffer[handler.next:]...)
+	data = append(data, handler.buffer[:handler.next]...)
+	if !handler.aligned {
+		// the oldest message starts before the buffer, skip what's left of it.
+		if end := bytes.IndexByte(data, '\n'); end >= 0 {
+			data = data[end+1:]
+		}
+	}
+	return data
+}
+
+// logging.CircularBufferHandler.Dump, write the kept messages to w, oldest first, like to a
+// file on a crash. Records handled meanwhile aren't blocked by a slow w.
+func (handler *CircularBufferHandler) Dump(w io.Writer) error {
+	_, err := w.Write(handler.Bytes())
+	return err
+}
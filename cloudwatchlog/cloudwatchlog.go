@@ -0,0 +1,306 @@
+/*
+Package cloudwatchlog sends the records of a logging.Logger to AWS CloudWatch Logs
+with PutLogEvents, without the CloudWatch agent. It's a separate package, so programs
+which don't use AWS don't depend on its SDK.
+
+	config, err := awsconfig.LoadDefaultConfig(ctx)
+	handler := &cloudwatchlog.Handler{
+		Client:    cloudwatchlogs.NewFromConfig(config),
+		LogGroup:  "/ecs/myapp",
+		LogStream: taskID,
+		Formatter: &logging.JSONFormatter{},
+	}
+	logger.Handlers = append(logger.Handlers, &logging.AsyncHandler{Handler: handler})
+	defer logger.Close()
+
+A full batch is sent by the caller which fills it, wrap the handler in a logging.AsyncHandler
+so callers don't wait for CloudWatch, like above.
+*/
+package cloudwatchlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+	"github.com/gamelife1314/logging"
+)
+
+// Limits of PutLogEvents.
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	eventOverhead  = 26 // bytes counted per event on top of its message.
+	maxEventBytes  = 262144 - eventOverhead
+	putInterval    = time.Second / 5 // at most 5 requests per second per stream.
+)
+
+// cloudwatchlog.Client, the calls of Handler to CloudWatch Logs, implemented by *cloudwatchlogs.Client.
+type Client interface {
+	PutLogEvents(ctx context.Context, input *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogGroup(ctx context.Context, input *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, input *cloudwatchlogs.CreateLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
+// cloudwatchlog.Handler, a logging.MessageHandler sending records to the LogStream of LogGroup,
+// both created when they're missing. Records are formatted as they arrive and sent in batches,
+// when BatchSize records or 1 MiB are queued, every FlushInterval, and on Flush and Close.
+// Requests are spaced to respect the rate limit of a stream, throttled ones are retried
+// with an exponential backoff, and the sequence token is kept from one request to the next.
+type Handler struct {
+	Level     logging.MessageLevel
+	Filter    logging.MessageFilter
+	Formatter logging.Formatter // Default: a logging.JSONFormatter, for CloudWatch Logs Insights.
+	Client    Client
+	LogGroup  string
+	LogStream string
+
+	BatchSize     int           // Default and maximum: 10000
+	FlushInterval time.Duration // Default: 5 seconds
+	MaxRetries    int           // retries of a throttled request, Default: 5
+
+	// ErrorHandler, called when a periodic flush fails, Default: print to stderr.
+	ErrorHandler func(err error)
+
+	mutex      sync.Mutex
+	levelMutex sync.RWMutex // guard Level, SetLevel changes it while records are handled.
+	events     []types.InputLogEvent
+	size       int
+	token      *string
+	lastPut    time.Time
+	stop       chan struct{}
+	closed     bool
+	jsonFormat logging.JSONFormatter // used if Formatter is nil, it has no state to guard.
+}
+
+// cloudwatchlog.Handler.Handle, queue the record of logger if it passes level and filter,
+// send the batch if it's full.
+func (handler *Handler) Handle(logger *logging.Logger) error {
+	record := logger.Record
+	if record.Level < handler.level() || (handler.Filter != nil && !handler.Filter(logger)) {
+		return nil
+	}
+	message := strings.TrimSuffix(handler.formatter().GetMessage(logger), "\n")
+	if len(message) > maxEventBytes {
+		end := maxEventBytes
+		for end > 0 && !utf8.RuneStart(message[end]) {
+			end-- // CloudWatch requires valid UTF-8, don't cut a rune.
+		}
+		message = message[:end]
+	}
+	if message == "" {
+		return nil // CloudWatch rejects empty messages.
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.closed {
+		return logging.ErrHandlerClosed
+	}
+	if handler.stop == nil {
+		interval := handler.FlushInterval
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+		handler.stop = make(chan struct{})
+		go handler.autoFlush(interval, handler.stop)
+	}
+
+	var err error
+	if handler.size+len(message)+eventOverhead > maxBatchBytes {
+		err = handler.flush()
+	}
+	handler.events = append(handler.events, types.InputLogEvent{
+		Message:   aws.String(message),
+		Timestamp: aws.Int64(record.Created.UnixMilli()),
+	})
+	handler.size += len(message) + eventOverhead
+
+	size := handler.BatchSize
+	if size <= 0 || size > maxBatchEvents {
+		size = maxBatchEvents
+	}
+	if len(handler.events) >= size {
+		err = errors.Join(err, handler.flush())
+	}
+	return err
+}
+
+// cloudwatchlog.Handler.SetLevel, set the minimum level of records to send.
+func (handler *Handler) SetLevel(level logging.MessageLevel) {
+	handler.levelMutex.Lock()
+	defer handler.levelMutex.Unlock()
+
+	handler.Level = level
+}
+
+func (handler *Handler) level() logging.MessageLevel {
+	handler.levelMutex.RLock()
+	defer handler.levelMutex.RUnlock()
+
+	return handler.Level
+}
+
+// cloudwatchlog.Handler.Flush, send the queued records.
+func (handler *Handler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.flush()
+}
+
+// cloudwatchlog.Handler.Close, send the queued records and stop the periodic flush.
+func (handler *Handler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.closed {
+		return nil
+	}
+	handler.closed = true
+	if handler.stop != nil {
+		close(handler.stop)
+	}
+	return handler.flush()
+}
+
+func (handler *Handler) formatter() logging.Formatter {
+	if handler.Formatter != nil {
+		return handler.Formatter
+	}
+	return &handler.jsonFormat
+}
+
+// flush, send the queued records in chronological order, as PutLogEvents requires. The caller
+// holds the lock of handler.
+func (handler *Handler) flush() error {
+	if len(handler.events) == 0 {
+		return nil
+	}
+	events := handler.events
+	handler.events = nil
+	handler.size = 0
+	sort.SliceStable(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+	return handler.put(context.Background(), events)
+}
+
+// put, send events, creating the group and the stream if they're missing, taking the sequence
+// token CloudWatch expects if ours is stale, and backing off while the request is throttled.
+func (handler *Handler) put(ctx context.Context, events []types.InputLogEvent) error {
+	retries := handler.MaxRetries
+	if retries <= 0 {
+		retries = 5
+	}
+	backoff := putInterval
+	created := false
+	for attempt := 0; ; attempt++ {
+		if wait := putInterval - time.Since(handler.lastPut); wait > 0 {
+			time.Sleep(wait)
+		}
+		handler.lastPut = time.Now()
+		output, err := handler.Client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(handler.LogGroup),
+			LogStreamName: aws.String(handler.LogStream),
+			LogEvents:     events,
+			SequenceToken: handler.token,
+		})
+		if err == nil {
+			handler.token = output.NextSequenceToken
+			if output.RejectedLogEventsInfo != nil {
+				return fmt.Errorf("cloudwatchlog: %s/%s rejected events too old, too new or expired", handler.LogGroup, handler.LogStream)
+			}
+			return nil
+		}
+
+		var (
+			accepted     *types.DataAlreadyAcceptedException
+			invalidToken *types.InvalidSequenceTokenException
+			notFound     *types.ResourceNotFoundException
+		)
+		switch {
+		case errors.As(err, &accepted):
+			handler.token = accepted.ExpectedSequenceToken
+			return nil
+		case errors.As(err, &invalidToken) && attempt < retries:
+			handler.token = invalidToken.ExpectedSequenceToken
+			continue
+		case errors.As(err, &notFound) && !created:
+			created = true
+			if err := handler.create(ctx); err != nil {
+				return err
+			}
+			continue
+		case throttled(err) && attempt < retries:
+			time.Sleep(backoff)
+			backoff *= 2
+			continue
+		}
+		return fmt.Errorf("cloudwatchlog: put %d events to %s/%s: %w", len(events), handler.LogGroup, handler.LogStream, err)
+	}
+}
+
+// create, create LogGroup and LogStream, unless they exist.
+func (handler *Handler) create(ctx context.Context) error {
+	var exists *types.ResourceAlreadyExistsException
+	_, err := handler.Client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(handler.LogGroup),
+	})
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("cloudwatchlog: create log group %s: %w", handler.LogGroup, err)
+	}
+	_, err = handler.Client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(handler.LogGroup),
+		LogStreamName: aws.String(handler.LogStream),
+	})
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("cloudwatchlog: create log stream %s/%s: %w", handler.LogGroup, handler.LogStream, err)
+	}
+	handler.token = nil // a new stream has no sequence token.
+	return nil
+}
+
+// throttled, tell if err is CloudWatch asking to slow down.
+func throttled(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "ServiceUnavailableException", "LimitExceededException":
+		return true
+	}
+	return false
+}
+
+// autoFlush, send the queued records every interval until stop is closed.
+func (handler *Handler) autoFlush(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := handler.Flush(); err != nil {
+				if handler.ErrorHandler != nil {
+					handler.ErrorHandler(err)
+				} else {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
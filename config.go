@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// logging.Config, JSON description of a logger read by LoadConfig, like:
+//
+//	{
+//	    "level": "info",
+//	    "handlers": [
+//	        {"type": "stream", "destination": "stderr", "formatter": "text"},
+//	        {"type": "rotating", "destination": "app.log", "level": "error",
+//	         "formatter": "json", "max_bytes": 10485760, "backup_count": 5}
+//	    ]
+//	}
+type Config struct {
+	Level    string          `json:"level"` // Default: "debug"
+	Handlers []HandlerConfig `json:"handlers"`
+}
+
+// logging.HandlerConfig, JSON description of a handler.
+type HandlerConfig struct {
+	// Type, one of "stream", "file", "rotating", "timed_rotating" and "null".
+	Type string `json:"type"`
+
+	// Destination, "stdout" or "stderr" for a stream handler, the file name for the others.
+	Destination string `json:"destination"`
+
+	Level string `json:"level"` // Default: "debug"
+
+	// Formatter, one of "text", "json" and "logfmt", Default: "text".
+	// Format is the template of the text formatter, Default: DefaultFormat.
+	// TimeFormat is a layout, or "unix" and "unixmilli" for numbers, see TimeFormatUnix.
+	Formatter  string `json:"formatter"`
+	Format     string `json:"format"`
+	TimeFormat string `json:"time_format"`
+
+	// options of the rotating handlers.
+	MaxBytes    int64  `json:"max_bytes"`
+	BackupCount int    `json:"backup_count"`
+	When        string `json:"when"`
+	Compress    bool   `json:"compress"`
+}
+
+// logging.LoadConfig, read a JSON Config from r and return the logger it describes.
+func LoadConfig(r io.Reader) (*Logger, error) {
+	var config Config
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, fmt.Errorf("logging: decode config: %v", err)
+	}
+	return config.NewLogger()
+}
+
+// logging.ConfigFromEnv, return a logger described by the environment variables
+// LOG_LEVEL, the level name, LOG_FORMAT, one of "text", "json" and "logfmt", and
+// LOG_FILE, the file to append to instead of stdout.
+func ConfigFromEnv() (*Logger, error) {
+	handler := HandlerConfig{
+		Type:        "stream",
+		Destination: "stdout",
+		Formatter:   os.Getenv("LOG_FORMAT"),
+	}
+	if file := os.Getenv("LOG_FILE"); file != "" {
+		handler.Type = "file"
+		handler.Destination = file
+	}
+	config := Config{Level: os.Getenv("LOG_LEVEL"), Handlers: []HandlerConfig{handler}}
+	return config.NewLogger()
+}
+
+// logging.Config.NewLogger, return the logger described by config.
+func (config Config) NewLogger() (*Logger, error) {
+	level, err := parseConfigLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+	logger := &Logger{Level: level}
+	for i, handlerConfig := range config.Handlers {
+		handler, err := handlerConfig.newHandler()
+		if err != nil {
+			logger.Close()
+			return nil, fmt.Errorf("logging: handler %d: %v", i, err)
+		}
+		logger.Handlers = append(logger.Handlers, handler)
+	}
+	return logger, nil
+}
+
+// newHandler, return the handler described by config.
+func (config HandlerConfig) newHandler() (MessageHandler, error) {
+	level, err := parseConfigLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := config.newFormatter()
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Type {
+	case "stream":
+		var destination io.Writer
+		switch config.Destination {
+		case "", "stdout":
+			destination = os.Stdout
+		case "stderr":
+			destination = os.Stderr
+		default:
+			return nil, fmt.Errorf("unknown stream destination %q", config.Destination)
+		}
+		return &StreamMessageHandler{Level: level, Formatter: formatter, Destination: destination}, nil
+	case "file":
+		file, _, err := openLogFile(config.Destination)
+		if err != nil {
+			return nil, err
+		}
+		return &FileMessageHandler{Level: level, Formatter: formatter, Destination: file}, nil
+	case "rotating":
+		return &RotatingFileHandler{
+			Level:       level,
+			Formatter:   formatter,
+			FileName:    config.Destination,
+			MaxBytes:    config.MaxBytes,
+			BackupCount: config.BackupCount,
+			Compress:    config.Compress,
+		}, nil
+	case "timed_rotating":
+		if config.When != "" && config.When != "midnight" && config.When != "hourly" {
+			return nil, fmt.Errorf("unknown rotation %q", config.When)
+		}
+		return &TimedRotatingFileHandler{
+			Level:       level,
+			Formatter:   formatter,
+			FileName:    config.Destination,
+			When:        config.When,
+			BackupCount: config.BackupCount,
+			Compress:    config.Compress,
+		}, nil
+	case "null":
+		return NullHandler{}, nil
+	}
+	return nil, fmt.Errorf("unknown handler type %q", config.Type)
+}
+
+// newFormatter, return the formatter described by config.
+func (config HandlerConfig) newFormatter() (Formatter, error) {
+	switch config.Formatter {
+	case "", "text":
+		format, timeFormat := config.Format, config.TimeFormat
+		if format == "" {
+			format = DefaultFormat
+		}
+		if timeFormat == "" {
+			timeFormat = DefaultTimeFormat
+		}
+		return NewMessageFormatter(format, timeFormat)
+	case "json":
+		return &JSONFormatter{TimeFormat: config.TimeFormat}, nil
+	case "logfmt":
+		return &LogfmtFormatter{TimeFormat: config.TimeFormat}, nil
+	}
+	return nil, fmt.Errorf("unknown formatter %q", config.Formatter)
+}
+
+func parseConfigLevel(s string) (MessageLevel, error) {
+	if s == "" {
+		return DEBUG, nil
+	}
+	return ParseLevel(s)
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"sync"
+)
+
+// logging.ContextExtractor, return a structured field carried by ctx, like a request id.
+type ContextExtractor func(ctx context.Context) (key string, value interface{}, ok bool)
+
+var (
+	extractorsMutex sync.RWMutex
+	extractors      []ContextExtractor
+)
+
+// logging.RegisterContextExtractor, add extractor to the ones run by Logger.WithContext.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	extractorsMutex.Lock()
+	defer extractorsMutex.Unlock()
+
+	extractors = append(extractors, extractor)
+}
+
+// Logger.WithContext, return a logger like WithFields, whose records carry the
+// fields found in ctx by every registered ContextExtractor, and ctx itself in
+// MessageRecord.Context, like for the trace of otellog.Handler.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	child := l.WithFields(contextFields(ctx))
+	child.ctx = ctx
+	return child
+}
+
+// scopeKey, the context key of the fields of WithScope.
+type scopeKey struct{}
+
+// logging.WithScope, return a copy of ctx carrying fields, over the ones of the scopes of ctx,
+// so every record logged with it, by DebugCtx, InfoCtx, etc. or a logger of WithContext, has
+// them, like a request id set once by a middleware for every call down the request.
+func WithScope(ctx context.Context, fields map[string]interface{}) context.Context {
+	return context.WithValue(ctx, scopeKey{}, mergeFields(ScopeFields(ctx), fields))
+}
+
+// logging.ScopeFields, return the fields of the scopes of ctx, nil if there are none, they
+// must not be modified.
+func ScopeFields(ctx context.Context) Fields {
+	fields, _ := ctx.Value(scopeKey{}).(Fields)
+	return fields
+}
+
+// contextFields, return the fields of the scopes of ctx and the ones found by every registered
+// ContextExtractor, which take precedence, nil if there are none.
+func contextFields(ctx context.Context) Fields {
+	fields := ScopeFields(ctx)
+	extractorsMutex.RLock()
+	defer extractorsMutex.RUnlock()
+
+	if len(extractors) == 0 {
+		return fields
+	}
+	merged := make(Fields, len(fields)+len(extractors))
+	for key, value := range fields {
+		merged[key] = value
+	}
+	for _, extractor := range extractors {
+		if key, value, ok := extractor(ctx); ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
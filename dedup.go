@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// logging.DedupFilter, drop records repeating the previous one, same level and
+// message, within Window of its first occurrence. When the run of duplicates
+// ends, because another record arrives or Window elapses, a summary like
+// `message "disk full" repeated 4213 times` is written at the same level, a copy
+// of the first record of the run but for its message. The summary is written on
+// its own goroutine, a record ending the run may come first, and it passes every
+// DedupFilter. Assign its Filter method to a Filter field:
+//
+//	dedup := &logging.DedupFilter{Window: time.Minute}
+//	logger.Filter = dedup.Filter
+type DedupFilter struct {
+	Window time.Duration
+
+	// Handler writes the summaries. Default: nil, the logger of the run logs them like its
+	// own records. Set it when the filter is the one of a handler, or of a handler inside an
+	// AsyncHandler, whose private logger has no handlers, usually to that handler.
+	Handler MessageHandler
+
+	mutex    sync.Mutex
+	logger   *Logger
+	first    MessageRecord // first record of the run, the summary is a copy of it.
+	last     samplingKey
+	since    time.Time
+	repeated int
+	timer    *time.Timer
+}
+
+// logging.DedupFilter.Filter, report whether the record of logger doesn't repeat the previous one.
+func (filter *DedupFilter) Filter(logger *Logger) bool {
+	record := logger.Record
+	if record.summary {
+		return true
+	}
+
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	key := samplingKey{level: record.Level, message: record.Message}
+	now := time.Now()
+	if filter.logger != nil && key == filter.last && now.Sub(filter.since) < filter.Window {
+		filter.repeated++
+		return false
+	}
+	filter.endRun()
+	filter.logger, filter.first, filter.last, filter.since = logger, *record, key, now
+	filter.timer = time.AfterFunc(filter.Window, filter.expire)
+	return true
+}
+
+// expire, end the run when Window elapsed without another record.
+func (filter *DedupFilter) expire() {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	filter.endRun()
+	filter.logger = nil
+	filter.first = MessageRecord{}
+}
+
+// endRun, write the summary of the current run if it has duplicates.
+func (filter *DedupFilter) endRun() {
+	if filter.timer != nil {
+		filter.timer.Stop()
+		filter.timer = nil
+	}
+	if filter.repeated == 0 {
+		return
+	}
+	summary := filter.first
+	summary.Message = fmt.Sprintf("message %q repeated %d times", filter.last.message, filter.repeated)
+	summary.Created = time.Now()
+	if filter.logger.Clock != nil {
+		summary.Created = filter.logger.Clock.Now()
+	}
+	summary.Errors, summary.Stack = nil, ""
+	summary.summary = true
+	filter.repeated = 0
+	go filter.write(filter.logger, filter.Handler, summary)
+}
+
+// write, pass summary to handler, or log it with logger if there's none.
+func (filter *DedupFilter) write(logger *Logger, handler MessageHandler, summary MessageRecord) {
+	if handler == nil {
+		logger.logRecord(&summary)
+		return
+	}
+	writer := &Logger{Level: DEBUG, ErrorHandler: logger.ErrorHandler, DefaultFormatter: logger.DefaultFormatter}
+	if err := handleRecord(handler, writer, &summary); err != nil {
+		writer.reportError(err)
+	}
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer, a buffer read by the test while handlers write to it on other goroutines.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+// waitFor, wait until buffer has want lines, or fail t after a second.
+func waitFor(t *testing.T, buffer *syncBuffer, want int) []string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+		if len(lines) >= want || time.Now().After(deadline) {
+			return lines
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDedupFilterInsideAsyncHandler(t *testing.T) {
+	buffer := new(syncBuffer)
+	dedup := &DedupFilter{Window: time.Minute}
+	stream := &StreamMessageHandler{Filter: dedup.Filter, Formatter: &MessageFormatter{Format: "{{.Message}}\n"}, Destination: buffer}
+	dedup.Handler = stream
+	other := &StreamMessageHandler{Formatter: &MessageFormatter{Format: "{{.Message}}\n"}, Destination: new(syncBuffer)}
+	async := &AsyncHandler{Handler: stream}
+	logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{async, other}}
+	defer logger.Close()
+
+	for _, message := range []string{"same", "same", "same", "other"} {
+		logger.InfoLiteral(message)
+	}
+	logger.Flush()
+	lines := waitFor(t, buffer, 3)
+	want := map[string]bool{"same": true, "other": true, `message "same" repeated 2 times`: true}
+	if len(lines) != len(want) {
+		t.Fatalf("output %q, want the lines of %v", lines, want)
+	}
+	for _, line := range lines {
+		if !want[line] {
+			t.Fatalf("output %q, want the lines of %v", lines, want)
+		}
+	}
+	if other.Destination.(*syncBuffer).String() != "same\nsame\nsame\nother\n" {
+		t.Fatalf("the summary went to another handler, %q", other.Destination.(*syncBuffer).String())
+	}
+}
+
+func TestDedupFilterSummaryText(t *testing.T) {
+	buffer := new(syncBuffer)
+	dedup := &DedupFilter{Window: time.Minute}
+	logger := &Logger{Level: DEBUG, Filter: dedup.Filter, Clock: FixedClock(testTime), Handlers: []MessageHandler{
+		&StreamMessageHandler{Formatter: &MessageFormatter{Format: "{{.Message}}\n"}, Destination: buffer},
+	}}
+
+	summary := `message "a" repeated 1 times`
+	logger.Info("a")
+	logger.Info("a")
+	logger.Info("b")
+	waitFor(t, buffer, 3)
+	// records with the text of a summary are deduplicated like any other.
+	logger.InfoLiteral(summary)
+	logger.InfoLiteral(summary)
+	logger.Info("c")
+	lines := waitFor(t, buffer, 6)
+	want := []string{"a", "b", summary, summary, "c", `message "message \"a\" repeated 1 times" repeated 1 times`}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("output %q, want %q", lines, want)
+	}
+}
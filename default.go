@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	defaultMutex  sync.RWMutex
+	defaultLogger = GetDefaultLogger()
+)
+
+// logging.SetDefault, replace the logger used by the package functions Debug, Info, etc.
+func SetDefault(logger *Logger) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	defaultLogger = logger
+}
+
+// logging.Default, return the logger used by the package functions, see SetDefault.
+func Default() *Logger {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+
+	return defaultLogger
+}
+
+// The package functions below record to the default logger, the caller of a
+// record is still the caller of the package function.
+
+// logging.Debug, record DEBUG message with the default logger.
+func Debug(format string, a ...interface{}) {
+	Default().log(DEBUG, format, a...)
+}
+
+// logging.Info, record INFO message with the default logger.
+func Info(format string, a ...interface{}) {
+	Default().log(INFO, format, a...)
+}
+
+// logging.Notice, record NOTICE message with the default logger.
+func Notice(format string, a ...interface{}) {
+	Default().log(NOTICE, format, a...)
+}
+
+// logging.Warning, record WARNING message with the default logger.
+func Warning(format string, a ...interface{}) {
+	Default().log(WARNING, format, a...)
+}
+
+// logging.Error, record ERROR message with the default logger.
+func Error(format string, a ...interface{}) {
+	Default().log(ERROR, format, a...)
+}
+
+// logging.Critical, record CRITICAL message with the default logger.
+func Critical(format string, a ...interface{}) {
+	Default().log(CRITICAL, format, a...)
+}
+
+// logging.Fatal, record CRITICAL message with the default logger, flush it, then exit with status 1.
+func Fatal(format string, a ...interface{}) {
+	Default().Fatal(format, a...)
+}
+
+// logging.Panic, record CRITICAL message with the default logger, flush it, then panic with the message.
+func Panic(format string, a ...interface{}) {
+	Default().Panic(format, a...)
+}
+
+// logging.Debugf, record DEBUG message with the default logger, format is always parsed.
+func Debugf(format string, a ...interface{}) {
+	Default().Debugf(format, a...)
+}
+
+// logging.Infof, record INFO message with the default logger, format is always parsed.
+func Infof(format string, a ...interface{}) {
+	Default().Infof(format, a...)
+}
+
+// logging.Noticef, record NOTICE message with the default logger, format is always parsed.
+func Noticef(format string, a ...interface{}) {
+	Default().Noticef(format, a...)
+}
+
+// logging.Warningf, record WARNING message with the default logger, format is always parsed.
+func Warningf(format string, a ...interface{}) {
+	Default().Warningf(format, a...)
+}
+
+// logging.Errorf, record ERROR message with the default logger, format is always parsed.
+func Errorf(format string, a ...interface{}) {
+	Default().Errorf(format, a...)
+}
+
+// logging.Criticalf, record CRITICAL message with the default logger, format is always parsed.
+func Criticalf(format string, a ...interface{}) {
+	Default().Criticalf(format, a...)
+}
+
+// logging.DebugCtx, record DEBUG message with the default logger and the fields of ctx.
+func DebugCtx(ctx context.Context, format string, a ...interface{}) {
+	Default().DebugCtx(ctx, format, a...)
+}
+
+// logging.InfoCtx, record INFO message with the default logger and the fields of ctx.
+func InfoCtx(ctx context.Context, format string, a ...interface{}) {
+	Default().InfoCtx(ctx, format, a...)
+}
+
+// logging.NoticeCtx, record NOTICE message with the default logger and the fields of ctx.
+func NoticeCtx(ctx context.Context, format string, a ...interface{}) {
+	Default().NoticeCtx(ctx, format, a...)
+}
+
+// logging.WarningCtx, record WARNING message with the default logger and the fields of ctx.
+func WarningCtx(ctx context.Context, format string, a ...interface{}) {
+	Default().WarningCtx(ctx, format, a...)
+}
+
+// logging.ErrorCtx, record ERROR message with the default logger and the fields of ctx.
+func ErrorCtx(ctx context.Context, format string, a ...interface{}) {
+	Default().ErrorCtx(ctx, format, a...)
+}
+
+// logging.CriticalCtx, record CRITICAL message with the default logger and the fields of ctx.
+func CriticalCtx(ctx context.Context, format string, a ...interface{}) {
+	Default().CriticalCtx(ctx, format, a...)
+}
+
+// logging.Flush, flush the handlers of the default logger, see Logger.Flush.
+func Flush() error {
+	return Default().Flush()
+}
+
+// logging.Close, flush and close the handlers of the default logger, so buffered and
+// asynchronous output isn't lost at shutdown. Defer it in main:
+//
+//	defer logging.Close()
+//
+// Deferred calls don't run on os.Exit, call Exit instead.
+func Close() error {
+	return Default().Close()
+}
+
+// logging.Exit, close the default logger, then exit with code, a replacement of os.Exit.
+func Exit(code int) {
+	if err := Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "logging: %v\n", err)
+	}
+	os.Exit(code)
+}
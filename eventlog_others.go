@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package logging
+
+import (
+	"errors"
+)
+
+// errNoEventLog, returned by the event log functions out of Windows.
+var errNoEventLog = errors.New("logging: the event log is only on windows")
+
+// logging.EventLogHandler, write records to the Windows event log. Out of Windows it discards
+// them, so programs configuring it build on every system.
+type EventLogHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+	Source    string
+	EventID   uint32
+}
+
+// logging.EventLogHandler.Handle, discard the record of logger.
+func (handler *EventLogHandler) Handle(logger *Logger) error {
+	return nil
+}
+
+// logging.EventLogHandler.SetLevel, set the minimum level of records to report.
+func (handler *EventLogHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.EventLogHandler.Close, do nothing.
+func (handler *EventLogHandler) Close() error {
+	return nil
+}
+
+// logging.InstallEventSource, return an error out of Windows.
+func InstallEventSource(source string) error {
+	return errNoEventLog
+}
+
+// logging.RemoveEventSource, return an error out of Windows.
+func RemoveEventSource(source string) error {
+	return errNoEventLog
+}
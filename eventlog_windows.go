@@ -0,0 +1,201 @@
+//go:build windows
+// +build windows
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Event types of ReportEvent.
+const (
+	eventlogErrorType       = 0x0001
+	eventlogWarningType     = 0x0002
+	eventlogInformationType = 0x0004
+
+	eventlogMaxMessage = 31839 // characters of a string of an event.
+	eventSourcesKey    = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+)
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSource   = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEvent           = advapi32.NewProc("ReportEventW")
+	procRegCreateKeyEx        = advapi32.NewProc("RegCreateKeyExW")
+	procRegSetValueEx         = advapi32.NewProc("RegSetValueExW")
+	procRegDeleteKey          = advapi32.NewProc("RegDeleteKeyW")
+)
+
+// logging.EventLogHandler, write records to the Application log of Windows, shown in Event
+// Viewer, as events of Source: ERROR and above are errors, WARNING warnings, the others
+// information. Source must be registered once, see InstallEventSource, or Event Viewer
+// shows the messages with a warning that their description is missing.
+type EventLogHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter // Default: the message alone, the event log records the time and the type.
+
+	// Source, the name of the event source, Default: the program name
+	Source string
+
+	// EventID of every event, from 1 to 1000 with InstallEventSource, Default: 1
+	EventID uint32
+
+	mutex  sync.Mutex
+	handle uintptr
+}
+
+// logging.EventLogHandler.Handle, report the record of logger if it passes level and filter.
+func (handler *EventLogHandler) Handle(logger *Logger) error {
+	formatter := handler.Formatter
+	if formatter == nil {
+		formatter = eventMessageFormatter{}
+	}
+	level := logger.Record.Level
+	return handle(logger, &handler.Level, handler.Filter, formatter, func(message []byte) error {
+		return handler.report(level, logger.Record.Program, message)
+	})
+}
+
+// logging.EventLogHandler.SetLevel, set the minimum level of records to report.
+func (handler *EventLogHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.EventLogHandler.Close, close the event log.
+func (handler *EventLogHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.handle == 0 {
+		return nil
+	}
+	r, _, err := procDeregisterEventSource.Call(handler.handle)
+	handler.handle = 0
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// report, report message as an event of the type of level, open the event log first if it isn't.
+func (handler *EventLogHandler) report(level MessageLevel, program string, message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.handle == 0 {
+		source := handler.Source
+		if source == "" {
+			source = program
+		}
+		name, err := syscall.UTF16PtrFromString(source)
+		if err != nil {
+			return err
+		}
+		h, _, err := procRegisterEventSource.Call(0, uintptr(unsafe.Pointer(name)))
+		if h == 0 {
+			return fmt.Errorf("logging: register event source %s: %w", source, err)
+		}
+		handler.handle = h
+	}
+
+	eventType := eventlogInformationType
+	switch {
+	case level >= ERROR:
+		eventType = eventlogErrorType
+	case level >= WARNING:
+		eventType = eventlogWarningType
+	}
+	eventID := handler.EventID
+	if eventID == 0 {
+		eventID = 1
+	}
+	text := []rune(strings.ReplaceAll(strings.TrimSuffix(string(message), "\n"), "\x00", ""))
+	if len(text) > eventlogMaxMessage {
+		text = text[:eventlogMaxMessage]
+	}
+	str, err := syscall.UTF16PtrFromString(string(text))
+	if err != nil {
+		return err
+	}
+	strs := []*uint16{str}
+	r, _, err := procReportEvent.Call(handler.handle, uintptr(eventType), 0, uintptr(eventID), 0,
+		uintptr(len(strs)), 0, uintptr(unsafe.Pointer(&strs[0])), 0)
+	if r == 0 {
+		return fmt.Errorf("logging: report event: %w", err)
+	}
+	return nil
+}
+
+// eventMessageFormatter, the default formatter of EventLogHandler, the message alone.
+type eventMessageFormatter struct{}
+
+func (formatter eventMessageFormatter) GetMessage(logger *Logger) string {
+	return logger.Record.Message
+}
+
+// logging.InstallEventSource, register source in the Application log, with the messages of
+// EventCreate.exe, which prints the message of an event as it is for event ids from 1 to 1000.
+// It writes to HKEY_LOCAL_MACHINE, so it needs administrator rights, like in an installer.
+func InstallEventSource(source string) error {
+	if source == "" {
+		return errors.New("logging: empty event source")
+	}
+	path, err := syscall.UTF16PtrFromString(eventSourcesKey + source)
+	if err != nil {
+		return err
+	}
+	var key syscall.Handle
+	r, _, _ := procRegCreateKeyEx.Call(uintptr(syscall.HKEY_LOCAL_MACHINE), uintptr(unsafe.Pointer(path)), 0, 0, 0,
+		uintptr(syscall.KEY_SET_VALUE), 0, uintptr(unsafe.Pointer(&key)), 0)
+	if r != 0 {
+		return fmt.Errorf("logging: install event source %s: %w", source, syscall.Errno(r))
+	}
+	defer syscall.RegCloseKey(key)
+
+	messageFile := syscall.StringToUTF16(`%SystemRoot%\System32\EventCreate.exe`)
+	if err := setRegistryValue(key, "EventMessageFile", syscall.REG_EXPAND_SZ,
+		unsafe.Pointer(&messageFile[0]), len(messageFile)*2); err != nil {
+		return fmt.Errorf("logging: install event source %s: %w", source, err)
+	}
+	types := uint32(eventlogErrorType | eventlogWarningType | eventlogInformationType)
+	if err := setRegistryValue(key, "TypesSupported", syscall.REG_DWORD, unsafe.Pointer(&types), 4); err != nil {
+		return fmt.Errorf("logging: install event source %s: %w", source, err)
+	}
+	return nil
+}
+
+// logging.RemoveEventSource, unregister source, like in an uninstaller.
+func RemoveEventSource(source string) error {
+	if source == "" {
+		return errors.New("logging: empty event source")
+	}
+	path, err := syscall.UTF16PtrFromString(eventSourcesKey + source)
+	if err != nil {
+		return err
+	}
+	r, _, _ := procRegDeleteKey.Call(uintptr(syscall.HKEY_LOCAL_MACHINE), uintptr(unsafe.Pointer(path)))
+	if r != 0 {
+		return fmt.Errorf("logging: remove event source %s: %w", source, syscall.Errno(r))
+	}
+	return nil
+}
+
+// setRegistryValue, set the value name of key to size bytes of data of type valueType.
+func setRegistryValue(key syscall.Handle, name string, valueType uint32, data unsafe.Pointer, size int) error {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	r, _, _ := procRegSetValueEx.Call(uintptr(key), uintptr(unsafe.Pointer(namePtr)), 0, uintptr(valueType), uintptr(data), uintptr(size))
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
@@ -1,4 +1,89 @@
 package logging
 
+import (
+	"fmt"
+	"regexp"
+)
+
 // logging.MessageFilter, for message filter.
 type MessageFilter func(logger *Logger) bool
+
+// logging.MessageHook, called with every record passing the filter of the logger,
+// before it's written by the handlers, like to count records per level. It runs under the
+// lock of the logger, so it must not log to it, it may to another one, like a clone of
+// WithField, and a panic goes to ErrorHandler once the lock is released. logger.Record
+// is reused once the record is handled, copy it to keep it after the call, or see SetRecordPool.
+type MessageHook func(logger *Logger)
+
+// logging.Processor, change a record passing the filter of the logger before the hooks and the
+// handlers see it, like to add a field, downgrade a noisy level or scrub the message, and return
+// it, or return another record to continue with, or nil to drop it. Fields may be shared with
+// the logger, change them with MessageRecord.SetField, not in place. Like a MessageHook, it
+// must not log to the logger, and must copy record to keep it after the call.
+type Processor func(record *MessageRecord) *MessageRecord
+
+// logging.LevelRangeFilter, return a filter passing only records whose level is in [min, max].
+func LevelRangeFilter(min, max MessageLevel) MessageFilter {
+	return func(logger *Logger) bool {
+		return logger.Record.Level >= min && logger.Record.Level <= max
+	}
+}
+
+// logging.ExactLevelFilter, return a filter passing only records of the given levels.
+func ExactLevelFilter(levels ...MessageLevel) MessageFilter {
+	accepted := make(map[MessageLevel]bool, len(levels))
+	for _, level := range levels {
+		accepted[level] = true
+	}
+	return func(logger *Logger) bool {
+		return accepted[logger.Record.Level]
+	}
+}
+
+// logging.AndFilter, return a filter passing records which pass every filter, evaluated in
+// order until one fails. A nil filter passes every record, like everywhere else.
+func AndFilter(filters ...MessageFilter) MessageFilter {
+	return func(logger *Logger) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(logger) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// logging.OrFilter, return a filter passing records which pass any filter, evaluated in
+// order until one passes. A nil filter passes every record, no filter passes none.
+func OrFilter(filters ...MessageFilter) MessageFilter {
+	return func(logger *Logger) bool {
+		for _, filter := range filters {
+			if filter == nil || filter(logger) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// logging.NotFilter, return a filter passing records which don't pass filter, a nil one
+// passes every record, so its negation passes none.
+func NotFilter(filter MessageFilter) MessageFilter {
+	return func(logger *Logger) bool {
+		return filter != nil && !filter(logger)
+	}
+}
+
+// logging.MessageRegexFilter, return a filter passing only records whose message matches
+// pattern if keep is set, or dropping them if it's not, like a health check:
+//
+//	filter, err := logging.MessageRegexFilter(`GET /healthz`, false)
+func MessageRegexFilter(pattern string, keep bool) (MessageFilter, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("logging: message filter: %w", err)
+	}
+	return func(logger *Logger) bool {
+		return re.MatchString(logger.Record.Message) == keep
+	}, nil
+}
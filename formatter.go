@@ -2,35 +2,523 @@ package logging
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"text/template"
 	"time"
+	"unicode/utf8"
 )
 
+// logging.Formatter, every formatter turns the current record of logger into a message string.
+type Formatter interface {
+	GetMessage(logger *Logger) string
+}
+
 // logging.MessageFormatter
 type MessageFormatter struct {
 
 	// Message Format, you can use any fields of MessageRecord.
+	// Structured fields are appended as key=value unless Format uses {{.Fields}}.
 	// Example: {{.Color}}[{{.Time}}] {{.LevelString}}  {{.FuncName}} {{.ShortFileName}} {{.Line}} {{.ColorClear}} {{.Message}}\n
 	Format string
 
-	// Message Time Format
+	// Message Time Format, "2006-01-02 15:04:05.000000" gives microseconds, see TimeFormatRFC3339, etc.
 	// Default: time.RFC1123
 	TimeFormat string
+
+	// Location of the time, like time.UTC.
+	// Default: time.Local
+	Location *time.Location
+
+	// MaxMessageLength, truncate messages longer than this many bytes, on a rune boundary,
+	// and append the original length, like "...(truncated, 1048576 bytes)".
+	// Default: 0, unlimited
+	MaxMessageLength int
+
+	// SortFields, write structured fields sorted by key, for a stable output.
+	// Default: false, in no particular order
+	SortFields bool
+
+	// MultilineMode, how the lines of a message spanning several ones, like with a stack
+	// trace, are written, so there's one record per line for parsers.
+	// Default: MultilineRaw, as they are
+	MultilineMode MultilineMode
+
+	// ColorWholeLine, color the whole line in the color of the level, the message too, in
+	// place of {{.Color}} and {{.ColorClear}}. No color like for them when it's disabled.
+	// Default: false, only between {{.Color}} and {{.ColorClear}}
+	ColorWholeLine bool
+
+	compiled atomic.Pointer[compiledFormat] // Format parsed on first use, or by NewMessageFormatter.
 }
 
+// compiledFormat, a Format and its template.
+type compiledFormat struct {
+	format   string
+	template *template.Template
+}
+
+// logging.NewMessageFormatter, return a formatter of format and timeFormat, whose template
+// is parsed once, now, so an invalid one is reported here rather than when logging.
+func NewMessageFormatter(format, timeFormat string) (*MessageFormatter, error) {
+	compiled, err := template.New("messageFormat").Parse(format)
+	if err == nil {
+		// unknown fields are only found by executing it.
+		err = compiled.Execute(io.Discard, MessageRecord{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("logging: invalid format: %w", err)
+	}
+	formatter := &MessageFormatter{Format: format, TimeFormat: timeFormat}
+	formatter.compiled.Store(&compiledFormat{format: format, template: compiled})
+	return formatter, nil
+}
+
+// defaultTemplate, DefaultFormat parsed, used in place of an invalid Format.
+var defaultTemplate = template.Must(template.New("messageFormat").Parse(DefaultFormat))
+
+// MessageFormatter.compile, return the template of Format, parsed once and again only if Format
+// changes, or DefaultFormat if it's invalid, like NewMessageFormatter tells. Concurrent first
+// uses may both parse it, one wins.
+func (formatter *MessageFormatter) compile() *template.Template {
+	format := formatter.Format
+	if compiled := formatter.compiled.Load(); compiled != nil && compiled.format == format {
+		return compiled.template
+	}
+	compiled, err := template.New("messageFormat").Parse(format)
+	if err == nil {
+		err = compiled.Execute(io.Discard, MessageRecord{})
+	}
+	if err != nil {
+		compiled = defaultTemplate
+	}
+	formatter.compiled.Store(&compiledFormat{format: format, template: compiled})
+	return compiled
+}
+
+// logging.MultilineMode, how MessageFormatter writes the line breaks of a message.
+type MultilineMode int
+
+const (
+	MultilineRaw    MultilineMode = iota // write line breaks as they are.
+	MultilineEscape                      // write line breaks as \n, a message stays on one line.
+	MultilineIndent                      // indent the continuation lines with a tab, under the first one.
+)
+
 // logging.MessageFormatter.GetMessage, return formatted message string for output.
 func (formatter *MessageFormatter) GetMessage(logger *Logger) string {
-	if formatter.TimeFormat == "" {
-		formatter.TimeFormat = time.RFC1123
-	}
-	logger.Record.Time = time.Now().Format(formatter.TimeFormat)
-	stringBuffer := new(bytes.Buffer)
-	tpl := template.Must(template.New("messageFormat").Parse(formatter.Format))
-	tpl.Execute(stringBuffer, *logger.Record)
-	message := stringBuffer.String()
-	if strings.Index(message, "\n") != len(message)-1 {
-		message += "\n"
-	}
-	return message
+	return formatString(formatter, logger)
+}
+
+// logging.MessageFormatter.WriteMessage, write the formatted message to buffer.
+func (formatter *MessageFormatter) WriteMessage(logger *Logger, buffer *bytes.Buffer) {
+	timeFormat := formatter.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC1123
+	}
+	logger.Record.Time = formatTime(logger.Record.Created, timeFormat, formatter.Location)
+	record := *logger.Record
+	record.Message = truncateMessage(record.Message, formatter.MaxMessageLength)
+	color, colorClear := record.Color, record.ColorClear
+	if formatter.ColorWholeLine {
+		record.Color, record.ColorClear = "", ""
+	}
+	start := buffer.Len()
+	if err := formatter.compile().Execute(buffer, record); err != nil {
+		// a template may still fail on some records, write the record with DefaultFormat.
+		logger.handleError(fmt.Errorf("logging: format: %w", err))
+		buffer.Truncate(start)
+		defaultTemplate.Execute(buffer, record)
+	}
+	if len(logger.Record.Fields) > 0 && !strings.Contains(formatter.Format, ".Fields") {
+		if buffer.Len() > 0 && buffer.Bytes()[buffer.Len()-1] == '\n' {
+			buffer.Truncate(buffer.Len() - 1)
+		}
+		buffer.WriteByte(' ')
+		buffer.WriteString(logger.Record.Fields.format(formatter.SortFields))
+	}
+	colored := formatter.ColorWholeLine && color != ""
+	if formatter.MultilineMode == MultilineRaw && !colored {
+		// the message is written as it is, without a copy.
+		if bytes.IndexByte(buffer.Bytes(), '\n') != buffer.Len()-1 {
+			buffer.WriteByte('\n')
+		}
+		return
+	}
+	message := buffer.String()
+	switch formatter.MultilineMode {
+	case MultilineEscape:
+		message = strings.ReplaceAll(strings.TrimSuffix(message, "\n"), "\n", `\n`) + "\n"
+	case MultilineIndent:
+		message = strings.ReplaceAll(strings.TrimSuffix(message, "\n"), "\n", "\n\t") + "\n"
+	default:
+		if strings.Index(message, "\n") != len(message)-1 {
+			message += "\n"
+		}
+	}
+	if colored {
+		// every line starts with the color and ends with its reset.
+		lines := strings.ReplaceAll(strings.TrimSuffix(message, "\n"), "\n", colorClear+"\n"+color)
+		message = color + lines + colorClear + "\n"
+	}
+	buffer.Reset()
+	buffer.WriteString(message)
+}
+
+// logging.JSONFormatter, output one json object per line, with keys:
+// time, level, logger if it's named, func, file, line, pid and goroutine if Logger.GoroutineID
+// is set, elapsed in seconds if Logger.Elapsed is set, message, and stack and errors if there're
+// some, followed by structured fields.
+// A field named like one of these keys is renamed to fields.<key>. A message which isn't
+// valid UTF-8 is base64 encoded, and followed by "message_encoding":"base64".
+type JSONFormatter struct {
+
+	// Message Time Format, time.RFC3339Nano gives nanoseconds, see TimeFormatRFC3339, etc.
+	// Default: time.RFC3339
+	TimeFormat string
+
+	// Location of the time, like time.UTC.
+	// Default: time.Local
+	Location *time.Location
+
+	// MaxMessageLength, truncate messages longer than this many bytes, on a rune boundary,
+	// and append the original length, like "...(truncated, 1048576 bytes)".
+	// Default: 0, unlimited
+	MaxMessageLength int
+
+	// SortFields, write structured fields sorted by key, for a stable output.
+	// Default: false, in no particular order
+	SortFields bool
+
+	// FieldNames, rename standard keys, like {"time": "@timestamp", "level": "severity"}.
+	// Default: nil, the keys above
+	FieldNames map[string]string
+
+	// OmitKeys, standard keys not written, like []string{"func", "line"} in production.
+	// Default: nil, all of them
+	OmitKeys []string
+}
+
+// logging.JSONFormatter.GetMessage, return json encoded message string for output.
+func (formatter *JSONFormatter) GetMessage(logger *Logger) string {
+	return formatString(formatter, logger)
+}
+
+// logging.JSONFormatter.WriteMessage, write the json encoded message to buffer.
+func (formatter *JSONFormatter) WriteMessage(logger *Logger, buffer *bytes.Buffer) {
+	timeFormat := formatter.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	logger.Record.Time = formatTime(logger.Record.Created, timeFormat, formatter.Location)
+	record := logger.Record
+	buffer.WriteByte('{')
+	// writeKey, write the name of key and a colon, unless it's omitted.
+	writeKey := func(key string) bool {
+		if formatter.omitted(key) {
+			return false
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		writeJSONString(buffer, formatter.name(key))
+		buffer.WriteByte(':')
+		return true
+	}
+	if writeKey("time") {
+		if numericTimeFormat(timeFormat) {
+			buffer.WriteString(record.Time)
+		} else {
+			writeJSONString(buffer, record.Time)
+		}
+	}
+	if writeKey("level") {
+		writeJSONString(buffer, record.LevelString)
+	}
+	if record.LoggerName != "" && writeKey("logger") {
+		writeJSONString(buffer, record.LoggerName)
+	}
+	if writeKey("func") {
+		writeJSONString(buffer, record.FuncName)
+	}
+	if writeKey("file") {
+		writeJSONString(buffer, record.ShortFileName)
+	}
+	if writeKey("line") {
+		buffer.WriteString(strconv.Itoa(record.Line))
+	}
+	if record.GoroutineID != 0 {
+		if writeKey("pid") {
+			buffer.WriteString(strconv.Itoa(record.Pid))
+		}
+		if writeKey("goroutine") {
+			buffer.WriteString(strconv.FormatUint(record.GoroutineID, 10))
+		}
+	}
+	if record.Elapsed != 0 && writeKey("elapsed") {
+		buffer.WriteString(strconv.FormatFloat(record.Elapsed.Seconds(), 'f', -1, 64))
+	}
+	message := truncateMessage(record.Message, formatter.MaxMessageLength)
+	if writeKey("message") {
+		if utf8.ValidString(message) {
+			writeJSONString(buffer, message)
+		} else {
+			writeJSONValue(buffer, []byte(message))
+			if writeKey("message_encoding") {
+				buffer.WriteString(`"base64"`)
+			}
+		}
+	}
+	if record.Stack != "" && writeKey("stack") {
+		writeJSONString(buffer, record.Stack)
+	}
+	if len(record.Errors) > 0 && writeKey("errors") {
+		writeJSONValue(buffer, record.Errors)
+	}
+	for _, name := range record.Fields.keys(formatter.SortFields) {
+		value := record.Fields[name]
+		if formatter.reserved(name) {
+			name = "fields." + name
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		writeJSONString(buffer, name)
+		buffer.WriteByte(':')
+		writeJSONValue(buffer, value)
+	}
+	buffer.WriteString("}\n")
+}
+
+// JSONFormatter.name, return the name of the standard key in the output.
+func (formatter *JSONFormatter) name(key string) string {
+	if name, ok := formatter.FieldNames[key]; ok && name != "" {
+		return name
+	}
+	return key
+}
+
+// JSONFormatter.omitted, tell if the standard key is in OmitKeys.
+func (formatter *JSONFormatter) omitted(key string) bool {
+	for _, omitted := range formatter.OmitKeys {
+		if omitted == key {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONFormatter.reserved, tell if a structured field named name would collide with a standard key.
+func (formatter *JSONFormatter) reserved(name string) bool {
+	if len(formatter.FieldNames) == 0 {
+		return jsonReservedKeys[name]
+	}
+	for key := range jsonReservedKeys {
+		if formatter.name(key) == name {
+			return true
+		}
+	}
+	return false
+}
+
+var jsonReservedKeys = map[string]bool{
+	"time": true, "level": true, "logger": true, "func": true, "file": true, "line": true, "pid": true, "goroutine": true, "elapsed": true,
+	"message": true, "message_encoding": true, "stack": true, "errors": true,
+}
+
+// TimeFormatRFC3339, etc., time formats of MessageFormatter, JSONFormatter and LogfmtFormatter.
+// TimeFormatUnix and TimeFormatUnixMilli aren't layouts, the time is the number of seconds or
+// milliseconds since the Unix epoch, a json number for JSONFormatter.
+const (
+	TimeFormatRFC3339     = time.RFC3339
+	TimeFormatRFC3339Nano = time.RFC3339Nano
+	TimeFormatKitchen     = time.Kitchen
+	TimeFormatUnix        = "unix"
+	TimeFormatUnixMilli   = "unixmilli"
+)
+
+// numericTimeFormat, tell if layout is TimeFormatUnix or TimeFormatUnixMilli.
+func numericTimeFormat(layout string) bool {
+	return layout == TimeFormatUnix || layout == TimeFormatUnixMilli
+}
+
+// formatTime, format t in location, the local one if it's nil, with layout, or as a number
+// for TimeFormatUnix and TimeFormatUnixMilli.
+func formatTime(t time.Time, layout string, location *time.Location) string {
+	switch layout {
+	case TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	if location == nil {
+		location = time.Local
+	}
+	return t.In(location).Format(layout)
+}
+
+// truncateMessage, return message cut to at most max bytes without splitting a rune, followed
+// by its original length, or message itself if max is zero or it's short enough.
+func truncateMessage(message string, max int) string {
+	if max <= 0 || len(message) <= max {
+		return message
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(truncated, %d bytes)", message[:cut], len(message))
+}
+
+// writeJSONValue, write value to buffer as json, or as a json string of its
+// default format if it can't be encoded. Strings which aren't valid UTF-8 are
+// base64 encoded, like []byte.
+func writeJSONValue(buffer *bytes.Buffer, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	if s, ok := value.(string); ok && !utf8.ValidString(s) {
+		value = []byte(s) // base64 like []byte, instead of replacing the invalid bytes.
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		writeJSONString(buffer, fmt.Sprint(value))
+		return
+	}
+	buffer.Write(encoded)
+}
+
+// writeJSONString, write s to buffer as a quoted and escaped json string.
+func writeJSONString(buffer *bytes.Buffer, s string) {
+	encoded, _ := json.Marshal(s)
+	buffer.Write(encoded)
+}
+
+// logging.LogfmtFormatter, output records as logfmt key=value pairs:
+// time, level, logger if it's named, func, file, line, pid and goroutine if Logger.GoroutineID
+// is set, elapsed if Logger.Elapsed is set, msg and stack if there's one, followed by structured fields.
+// Values containing spaces, quotes or equals signs are quoted.
+type LogfmtFormatter struct {
+
+	// Message Time Format, time.RFC3339Nano gives nanoseconds, see TimeFormatRFC3339, etc.
+	// Default: time.RFC3339
+	TimeFormat string
+
+	// Location of the time, like time.UTC.
+	// Default: time.Local
+	Location *time.Location
+
+	// MaxMessageLength, truncate messages longer than this many bytes, on a rune boundary,
+	// and append the original length, like "...(truncated, 1048576 bytes)".
+	// Default: 0, unlimited
+	MaxMessageLength int
+
+	// SortFields, write structured fields sorted by key, for a stable output.
+	// Default: false, in no particular order
+	SortFields bool
+}
+
+// logging.LogfmtFormatter.GetMessage, return logfmt encoded message string for output.
+func (formatter *LogfmtFormatter) GetMessage(logger *Logger) string {
+	return formatString(formatter, logger)
+}
+
+// logging.LogfmtFormatter.WriteMessage, write the logfmt encoded message to buffer.
+func (formatter *LogfmtFormatter) WriteMessage(logger *Logger, buffer *bytes.Buffer) {
+	timeFormat := formatter.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	logger.Record.Time = formatTime(logger.Record.Created, timeFormat, formatter.Location)
+	record := logger.Record
+	writeLogfmtPair(buffer, "time", record.Time)
+	writeLogfmtPair(buffer, "level", record.LevelString)
+	if record.LoggerName != "" {
+		writeLogfmtPair(buffer, "logger", record.LoggerName)
+	}
+	writeLogfmtPair(buffer, "func", record.FuncName)
+	writeLogfmtPair(buffer, "file", record.ShortFileName)
+	writeLogfmtPair(buffer, "line", strconv.Itoa(record.Line))
+	if record.GoroutineID != 0 {
+		writeLogfmtPair(buffer, "pid", strconv.Itoa(record.Pid))
+		writeLogfmtPair(buffer, "goroutine", strconv.FormatUint(record.GoroutineID, 10))
+	}
+	if record.Elapsed != 0 {
+		writeLogfmtPair(buffer, "elapsed", record.Elapsed.String())
+	}
+	writeLogfmtPair(buffer, "msg", truncateMessage(record.Message, formatter.MaxMessageLength))
+	if record.Stack != "" {
+		writeLogfmtPair(buffer, "stack", record.Stack)
+	}
+	fields := record.Fields.flat()
+	for _, key := range fields.keys(formatter.SortFields) {
+		writeLogfmtPair(buffer, key, fmt.Sprint(fields[key]))
+	}
+	buffer.WriteByte('\n')
+}
+
+// writeLogfmtPair, write key=value to buffer, separated from the previous pair by a space.
+func writeLogfmtPair(buffer *bytes.Buffer, key, value string) {
+	if buffer.Len() > 0 {
+		buffer.WriteByte(' ')
+	}
+	buffer.WriteString(key)
+	buffer.WriteByte('=')
+	if value == "" || strings.ContainsAny(value, " =\"") || strconv.Quote(value) != `"`+value+`"` {
+		buffer.WriteString(strconv.Quote(value))
+		return
+	}
+	buffer.WriteString(value)
+}
+
+// logging.LevelFormatter, format every record with the formatter of its level range, like a lean
+// format below WARNING and one with the caller and the stack from WARNING:
+//
+//	&LevelFormatter{FormatByLevel: map[MessageLevel]Formatter{
+//		DEBUG:   &MessageFormatter{Format: "{{.Time}} {{.LevelString}} {{.Message}}\n"},
+//		WARNING: &MessageFormatter{Format: "{{.Time}} {{.LevelString}} {{.FuncName}} {{.ShortFileName}}:{{.Line}} {{.Message}}\n{{.Stack}}"},
+//	}}
+//
+// A key is the lowest level of its range, which goes up to the next key.
+type LevelFormatter struct {
+	FormatByLevel map[MessageLevel]Formatter
+
+	// Default, the formatter of records below every key, Default: the formatter of the logger
+	Default Formatter
+}
+
+// logging.LevelFormatter.GetMessage, return the record of logger formatted by the formatter of its level.
+func (formatter *LevelFormatter) GetMessage(logger *Logger) string {
+	return formatter.choose(logger).GetMessage(logger)
+}
+
+// logging.LevelFormatter.WriteMessage, write the record of logger formatted by the formatter of its level to buffer.
+func (formatter *LevelFormatter) WriteMessage(logger *Logger, buffer *bytes.Buffer) {
+	chosen := formatter.choose(logger)
+	if bufferFormatter, ok := chosen.(BufferFormatter); ok {
+		bufferFormatter.WriteMessage(logger, buffer)
+		return
+	}
+	buffer.WriteString(chosen.GetMessage(logger))
+}
+
+// LevelFormatter.choose, return the formatter of the level of the record of logger.
+func (formatter *LevelFormatter) choose(logger *Logger) Formatter {
+	level := logger.Record.Level
+	chosen := formatter.Default
+	var bucket MessageLevel
+	found := false
+	for lowest, candidate := range formatter.FormatByLevel {
+		if lowest <= level && (!found || lowest > bucket) {
+			chosen, bucket, found = candidate, lowest, true
+		}
+	}
+	if chosen == nil {
+		chosen = logger.formatter()
+	}
+	return chosen
 }
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// BenchmarkMessageFormatterCompiled and BenchmarkMessageFormatterParsed, compare formatting
+// with the template parsed once with parsing it for every record, like before it was cached.
+func BenchmarkMessageFormatterCompiled(b *testing.B) {
+	benchmarkMessageFormatter(b, false)
+}
+
+func BenchmarkMessageFormatterParsed(b *testing.B) {
+	benchmarkMessageFormatter(b, true)
+}
+
+func benchmarkMessageFormatter(b *testing.B, parse bool) {
+	logger := GetDiscardLogger()
+	formatter := &MessageFormatter{Format: DefaultFormat, TimeFormat: DefaultTimeFormat}
+	logger.Record = newMessageRecord(0, false, INFO, "hello world")
+	defer releaseRecord(logger.Record)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if parse {
+			formatter.compiled.Store(nil)
+		}
+		formatter.GetMessage(logger)
+	}
+}
+
+func TestSharedFormatterDefaults(t *testing.T) {
+	formatters := []Formatter{&MessageFormatter{Format: "{{.Time}} {{.Message}}"}, &JSONFormatter{}, &LogfmtFormatter{}, &GELFFormatter{}}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		// loggers don't share a lock, only the formatters.
+		logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{
+			&StreamMessageHandler{Formatter: formatters[0], Destination: io.Discard},
+			&StreamMessageHandler{Formatter: formatters[1], Destination: io.Discard},
+			&StreamMessageHandler{Formatter: formatters[2], Destination: io.Discard},
+			&StreamMessageHandler{Formatter: formatters[3], Destination: io.Discard},
+		}}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.Info("hello")
+			}
+		}()
+	}
+	wg.Wait()
+	if formatters[0].(*MessageFormatter).TimeFormat != "" || formatters[3].(*GELFFormatter).Host != "" {
+		t.Fatal("formatting changed the configuration of the formatters")
+	}
+}
+
+func TestMessageFormatterUnknownField(t *testing.T) {
+	logger, buffer := NewTestLogger()
+	var errs []error
+	logger.ErrorHandler = func(err error) { errs = append(errs, err) }
+	formatter := &MessageFormatter{Format: "{{.Time}} {{.Nope}} {{.Message}}\n"}
+	logger.StreamHandler.Formatter = formatter
+
+	logger.Info("unknown field")
+	if !strings.Contains(buffer.String(), "unknown field") {
+		t.Fatalf("output %q, want the message with DefaultFormat", buffer.String())
+	}
+
+	// a changed Format is checked again.
+	buffer.Reset()
+	formatter.Format = "{{.Message}}\n"
+	logger.Info("valid")
+	formatter.Format = "{{.Message.Nope}}\n"
+	logger.Info("invalid")
+	if !strings.HasPrefix(buffer.String(), "valid\n") || !strings.Contains(buffer.String(), "invalid") {
+		t.Fatalf("output %q, want valid then invalid with DefaultFormat", buffer.String())
+	}
+
+	// a template failing on some records only writes them with DefaultFormat and reports it.
+	buffer.Reset()
+	formatter.Format = "{{.Fields.key.Nope}} {{.Message}}\n"
+	logger.WithField("key", 1).Info("failed")
+	if !strings.Contains(buffer.String(), "failed") || len(errs) != 1 || !strings.Contains(errs[0].Error(), "logging: format:") {
+		t.Fatalf("output %q and errors %v, want the message with DefaultFormat and a format error", buffer.String(), errs)
+	}
+	if errors.Unwrap(errs[0]) == nil {
+		t.Fatalf("error %v doesn't wrap the template error", errs[0])
+	}
+}
@@ -0,0 +1,201 @@
+package logging
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// syslogSeverity, return the syslog severity of level, from 2 for CRITICAL to 7 for DEBUG.
+func syslogSeverity(level MessageLevel) int {
+	switch {
+	case level >= CRITICAL:
+		return 2
+	case level >= ERROR:
+		return 3
+	case level >= WARNING:
+		return 4
+	case level >= NOTICE:
+		return 5
+	case level >= INFO:
+		return 6
+	}
+	return 7
+}
+
+// logging.GELFFormatter, output records as Graylog Extended Log Format 1.1
+// json objects: the message is short_message, the level is the syslog
+// severity, the caller is _func, _file and _line, structured fields are
+// additional fields prefixed with "_".
+type GELFFormatter struct {
+
+	// Host of the messages, Default: the hostname
+	Host string
+}
+
+// hostname, return the hostname, looked up once.
+var hostname = sync.OnceValue(func() string {
+	name, _ := os.Hostname()
+	return name
+})
+
+// logging.GELFFormatter.GetMessage, return the GELF json of the record of logger.
+func (formatter *GELFFormatter) GetMessage(logger *Logger) string {
+	return formatString(formatter, logger)
+}
+
+// logging.GELFFormatter.WriteMessage, write the GELF json of the record of logger to buffer.
+func (formatter *GELFFormatter) WriteMessage(logger *Logger, buffer *bytes.Buffer) {
+	host := formatter.Host
+	if host == "" {
+		host = hostname()
+	}
+	record := logger.Record
+	created := record.Created
+	buffer.WriteString(`{"version":"1.1","host":`)
+	writeJSONString(buffer, host)
+	buffer.WriteString(`,"short_message":`)
+	writeJSONString(buffer, record.Message)
+	if record.Stack != "" {
+		buffer.WriteString(`,"full_message":`)
+		writeJSONString(buffer, record.Message+"\n"+record.Stack)
+	}
+	buffer.WriteString(`,"timestamp":`)
+	buffer.WriteString(strconv.FormatFloat(float64(created.UnixNano())/1e9, 'f', 3, 64))
+	buffer.WriteString(`,"level":`)
+	buffer.WriteString(strconv.Itoa(syslogSeverity(record.Level)))
+	buffer.WriteString(`,"_func":`)
+	writeJSONString(buffer, record.FuncName)
+	buffer.WriteString(`,"_file":`)
+	writeJSONString(buffer, record.ShortFileName)
+	buffer.WriteString(`,"_line":`)
+	buffer.WriteString(strconv.Itoa(record.Line))
+	// additional fields are strings or numbers.
+	for key, value := range record.Fields.flat() {
+		// _id is reserved by GELF.
+		if key == "id" {
+			key = "field_id"
+		}
+		buffer.WriteByte(',')
+		writeJSONString(buffer, "_"+key)
+		buffer.WriteByte(':')
+		writeJSONValue(buffer, value)
+	}
+	buffer.WriteString("}")
+}
+
+const (
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+)
+
+// logging.GELFHandler, send records to a Graylog GELF UDP input at Address,
+// like "graylog.example.com:12201". Messages bigger than ChunkSize are split
+// into GELF chunks. Formatter defaults to a GELFFormatter.
+type GELFHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+	Address   string
+
+	// ChunkSize, the biggest datagram sent, Default: 1420 bytes
+	ChunkSize int
+
+	// WriteTimeout, abandon a message which isn't sent in time, connecting included, the
+	// error wraps context.DeadlineExceeded and os.ErrDeadlineExceeded. Default: 0, no limit
+	WriteTimeout time.Duration
+
+	mutex      sync.Mutex
+	conn       net.Conn
+	gelfFormat GELFFormatter
+}
+
+// logging.GELFHandler.Handle, send the record of logger if it passes level and filter.
+func (handler *GELFHandler) Handle(logger *Logger) error {
+	formatter := handler.Formatter
+	if formatter == nil {
+		formatter = &handler.gelfFormat
+	}
+	return handle(logger, &handler.Level, handler.Filter, formatter, handler.Write)
+}
+
+// logging.GELFHandler.SetLevel, set the minimum level of records to send.
+func (handler *GELFHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.GELFHandler.Write, send message in one datagram, or in chunks if it's too big.
+func (handler *GELFHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		conn, err := net.DialTimeout("udp", handler.Address, handler.WriteTimeout)
+		if err != nil {
+			return deadlineError(err)
+		}
+		handler.conn = conn
+	}
+	if handler.WriteTimeout > 0 {
+		handler.conn.SetWriteDeadline(time.Now().Add(handler.WriteTimeout))
+	}
+	chunkSize := handler.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 1420
+	}
+	if len(message) <= chunkSize {
+		_, err := handler.conn.Write(message)
+		return deadlineError(err)
+	}
+	return deadlineError(handler.writeChunks(message, chunkSize))
+}
+
+// logging.GELFHandler.Close, close the connection.
+func (handler *GELFHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+	err := handler.conn.Close()
+	handler.conn = nil
+	return err
+}
+
+// writeChunks, send message as GELF chunks: magic bytes, message id, sequence number and count, data.
+func (handler *GELFHandler) writeChunks(message []byte, chunkSize int) error {
+	dataSize := chunkSize - gelfChunkHeaderSize
+	if dataSize <= 0 {
+		return fmt.Errorf("logging: GELF chunk size %d is too small", chunkSize)
+	}
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return errors.New("logging: GELF message is too big")
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, chunkSize)
+	for i := 0; i < count; i++ {
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		end := (i + 1) * dataSize
+		if end > len(message) {
+			end = len(message)
+		}
+		chunk = append(chunk, message[i*dataSize:end]...)
+		if _, err := handler.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
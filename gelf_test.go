@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGELFHandlerWriteTimeout(t *testing.T) {
+	// a udp listener which never reads, a datagram write doesn't block, so the deadline is
+	// already over when it's written.
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	handler := &GELFHandler{Address: listener.LocalAddr().String(), WriteTimeout: time.Nanosecond}
+	defer handler.Close()
+
+	for _, message := range [][]byte{[]byte(`{"short_message":"stalled"}`), stalledMessage[:10000]} {
+		start := time.Now()
+		err := handler.Write(message)
+		assertTimeout(t, err, time.Since(start), handler.WriteTimeout)
+	}
+}
@@ -1,33 +1,352 @@
 package logging
 
 import (
+	"bufio"
+	"errors"
 	"io"
+	"os"
+	"sync"
+	"time"
 )
 
+// logging.MessageHandler, every handler checks its own level and filter
+// against the current record of logger, then formats and writes it. The record
+// is reused once Handle returns, a handler writing it later copies it, see MessageRecord.
 type MessageHandler interface {
-	Write(message []byte)
+	Handle(logger *Logger) error
+}
+
+// logging.Flusher, implemented by handlers buffering records, see Logger.Flush.
+type Flusher interface {
+	Flush() error
+}
+
+// logging.Closer, implemented by handlers holding resources, see Logger.Close.
+type Closer interface {
+	Close() error
+}
+
+// logging.Reopener, implemented by handlers writing to files, which close them and open them
+// again by path, see Logger.Reopen, and by the ones wrapping handlers, like AsyncHandler,
+// BatchingHandler, MemoryHandler and TeeHandler, which reopen them.
+type Reopener interface {
+	Reopen() error
+}
+
+// closeHandler, close handler if it's a Closer, or flush it if it's a Flusher.
+func closeHandler(handler MessageHandler) error {
+	if closer, ok := handler.(Closer); ok {
+		return closer.Close()
+	}
+	if flusher, ok := handler.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// logging.LevelSetter, implemented by handlers whose level can be changed with
+// Logger.SetLevel and Logger.SetHandlerLevel.
+type LevelSetter interface {
+	SetLevel(level MessageLevel)
+}
+
+// handle, format the record of logger and pass it to write if it passes level and filter.
+// A nil formatter falls back to the formatter of logger. The message of a BufferFormatter is
+// in a pooled buffer, write must copy it to keep it after it returns.
+func handle(logger *Logger, level *MessageLevel, filter MessageFilter, formatter Formatter, write func(message []byte) error) error {
+	if logger.Record.Level >= loadLevel(level) {
+		if filter == nil || filter(logger) {
+			if formatter == nil {
+				formatter = logger.formatter()
+			}
+			bufferFormatter, ok := formatter.(BufferFormatter)
+			if !ok {
+				return write([]byte(formatter.GetMessage(logger)))
+			}
+			buffer := getBuffer()
+			defer putBuffer(buffer)
+			bufferFormatter.WriteMessage(logger, buffer)
+			return write(buffer.Bytes())
+		}
+	}
+	return nil
+}
+
+// handlerLevelMutex, guard the Level of the handlers, SetLevel changes it while other goroutines
+// handle records, like the workers of an AsyncHandler or loggers sharing a handler.
+var handlerLevelMutex sync.RWMutex
+
+// loadLevel, return the Level of a handler, see storeLevel.
+func loadLevel(level *MessageLevel) MessageLevel {
+	handlerLevelMutex.RLock()
+	defer handlerLevelMutex.RUnlock()
+
+	return *level
+}
+
+// storeLevel, set the Level of a handler, for SetLevel.
+func storeLevel(level *MessageLevel, value MessageLevel) {
+	handlerLevelMutex.Lock()
+	defer handlerLevelMutex.Unlock()
+
+	*level = value
+}
+
+// handleRecord, pass record to handler through logger, a private logger of the
+// caller, for handlers keeping records to write them later.
+func handleRecord(handler MessageHandler, logger *Logger, record *MessageRecord) error {
+	logger.Record = record
+	logger.Fields = record.Fields
+	return handler.Handle(logger)
 }
 
 // logging.StreamMessageHandler
 type StreamMessageHandler struct {
 	Level       MessageLevel
 	Filter      MessageFilter
-	Formatter   *MessageFormatter
+	Formatter   Formatter
 	Destination io.Writer
+
+	// NoColor makes {{.Color}} and {{.ColorClear}} empty. They're always empty
+	// when Destination isn't a terminal, like a file or a pipe, or is a Windows
+	// console without virtual terminal processing.
+	NoColor bool
+
+	mutex     sync.Mutex // every message is written to Destination at once.
+	colorFile *os.File   // Destination when color was checked.
+	color     bool       // colorFile is a terminal showing colors.
+}
+
+// logging.StreamMessageHandler.Handle, write the record of logger if it passes level and filter.
+func (handler *StreamMessageHandler) Handle(logger *Logger) error {
+	handler.mutex.Lock()
+	color := handler.colored()
+	handler.mutex.Unlock()
+	if handler.NoColor || !color {
+		record := logger.Record
+		color, colorClear := record.Color, record.ColorClear
+		record.Color, record.ColorClear = "", ""
+		defer func() {
+			record.Color, record.ColorClear = color, colorClear
+		}()
+	}
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, handler.Write)
 }
 
-func (handler StreamMessageHandler) Write(message []byte) {
-	handler.Destination.Write(message)
+// logging.StreamMessageHandler.SetLevel, set the minimum level of records to write.
+func (handler *StreamMessageHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.StreamMessageHandler.SetDestination, replace Destination with destination and return
+// the previous one, like to restore it later. A message being written goes entirely to one of them.
+func (handler *StreamMessageHandler) SetDestination(destination io.Writer) io.Writer {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	previous := handler.Destination
+	handler.Destination = destination
+	handler.colored()
+	return previous
+}
+
+// colored, report whether Destination is a terminal showing colors, checked again only when
+// it changes, not for every record. The caller holds the lock of handler.
+func (handler *StreamMessageHandler) colored() bool {
+	file, ok := handler.Destination.(*os.File)
+	if !ok {
+		return false
+	}
+	if file != handler.colorFile {
+		handler.colorFile, handler.color = file, isTerminal(file) && enableColor(file)
+	}
+	return handler.color
+}
+
+// logging.StreamMessageHandler.Write, write message to Destination, which can be any io.Writer,
+// like a bytes.Buffer or an io.MultiWriter. Concurrent messages don't interleave.
+func (handler *StreamMessageHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	_, err := handler.Destination.Write(message)
+	return err
 }
 
 // logging.FileMessageHandler
 type FileMessageHandler struct {
 	Level       MessageLevel
 	Filter      MessageFilter
-	Formatter   *MessageFormatter
+	Formatter   Formatter
 	Destination io.Writer
+
+	// BufferSize, buffer messages up to this many bytes before writing them to
+	// Destination, Default: 0, write every message at once. Buffered messages are
+	// written by Flush, Close, every FlushInterval and every FlushEveryN messages if
+	// they're set, whichever comes first. Smaller values lose less on a crash, at
+	// the cost of more writes.
+	BufferSize    int
+	FlushInterval time.Duration
+	FlushEveryN   int
+
+	// Path of the file of NewFileHandler, opened again by Reopen.
+	Path string
+
+	mutex   sync.Mutex // every message is written to Destination at once.
+	buffer  *bufio.Writer
+	pending int // messages buffered since the last flush.
+	stop    chan struct{}
+}
+
+var (
+	_ MessageHandler = (*FileMessageHandler)(nil)
+	_ LevelSetter    = (*FileMessageHandler)(nil)
+	_ Flusher        = (*FileMessageHandler)(nil)
+	_ Closer         = (*FileMessageHandler)(nil)
+	_ Reopener       = (*FileMessageHandler)(nil)
+)
+
+// logging.NewFileHandler, return a handler of level writing to the file at path, opened in
+// append mode and created with permissions 0644 if it's missing. A nil formatter falls back
+// to the formatter of the logger.
+func NewFileHandler(path string, level MessageLevel, formatter *MessageFormatter) (*FileMessageHandler, error) {
+	file, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	handler := &FileMessageHandler{Level: level, Destination: file, Path: path}
+	if formatter != nil {
+		handler.Formatter = formatter
+	}
+	return handler, nil
+}
+
+// logFileMode, permissions of the log files the handlers create, backups and archives too.
+const logFileMode os.FileMode = 0644
+
+// openFile, open path in append mode, create it with permissions 0644 if it's missing.
+func openFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, logFileMode)
+}
+
+// logging.FileMessageHandler.Handle, write the record of logger if it passes level and filter.
+func (handler *FileMessageHandler) Handle(logger *Logger) error {
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, handler.Write)
+}
+
+// logging.FileMessageHandler.SetLevel, set the minimum level of records to write.
+func (handler *FileMessageHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.FileMessageHandler.Write, write message to Destination, or to the buffer if BufferSize is set,
+// concurrent messages don't interleave.
+func (handler *FileMessageHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.BufferSize <= 0 {
+		_, err := handler.Destination.Write(message)
+		return err
+	}
+	if handler.buffer == nil {
+		handler.buffer = bufio.NewWriterSize(handler.Destination, handler.BufferSize)
+		if handler.FlushInterval > 0 {
+			handler.stop = make(chan struct{})
+			go handler.autoFlush(handler.FlushInterval, handler.stop)
+		}
+	}
+	_, err := handler.buffer.Write(message)
+	handler.pending++
+	if err == nil && handler.FlushEveryN > 0 && handler.pending >= handler.FlushEveryN {
+		err = handler.flush()
+	}
+	return err
+}
+
+// logging.FileMessageHandler.Flush, write the buffered messages to Destination.
+func (handler *FileMessageHandler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.flush()
+}
+
+// logging.FileMessageHandler.Close, flush, then close Destination if it's an io.Closer, like an *os.File.
+func (handler *FileMessageHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.flush()
+	if handler.stop != nil {
+		close(handler.stop)
+		handler.stop = nil
+	}
+	handler.buffer = nil
+	if closer, ok := handler.Destination.(io.Closer); ok {
+		return errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// logging.FileMessageHandler.Reopen, flush, then if Destination is an *os.File, open Path, or
+// the name of the file, again and close it, so writes go to a fresh file after it's been moved.
+// Other destinations are only flushed.
+func (handler *FileMessageHandler) Reopen() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.flush()
+	file, ok := handler.Destination.(*os.File)
+	if !ok {
+		return err
+	}
+	path := handler.Path
+	if path == "" {
+		path = file.Name()
+	}
+	reopened, openErr := openFile(path)
+	if openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	handler.Destination = reopened
+	if handler.buffer != nil {
+		handler.buffer.Reset(reopened)
+	}
+	return errors.Join(err, file.Close())
+}
+
+func (handler *FileMessageHandler) flush() error {
+	if handler.buffer == nil {
+		return nil
+	}
+	handler.pending = 0
+	return handler.buffer.Flush()
+}
+
+// autoFlush, flush every interval until stop is closed.
+func (handler *FileMessageHandler) autoFlush(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			handler.Flush()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// logging.NullHandler, discard every record without formatting it.
+type NullHandler struct{}
+
+// logging.NullHandler.Handle, do nothing.
+func (handler NullHandler) Handle(logger *Logger) error {
+	return nil
 }
 
-func (handler FileMessageHandler) Write(message []byte) {
-	handler.Destination.Write(message)
+// logging.NullHandler.Write, do nothing.
+func (handler NullHandler) Write(message []byte) error {
+	return nil
 }
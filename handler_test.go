@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// byteWriter, write a byte at a time and yield between them, so unsynchronized writers interleave.
+type byteWriter struct {
+	buffer bytes.Buffer
+}
+
+func (w *byteWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		w.buffer.WriteByte(b)
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
+func TestStreamMessageHandlerConcurrentWrites(t *testing.T) {
+	const goroutines, messages = 20, 200
+	destination, copied := new(byteWriter), new(bytes.Buffer)
+	handler := &StreamMessageHandler{
+		Formatter:   &MessageFormatter{Format: "{{.Message}}\n"},
+		Destination: io.MultiWriter(destination, copied),
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		// loggers don't share a lock, only the handler serializes their writes.
+		logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{handler}}
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < messages; i++ {
+				if i%2 == 0 {
+					logger.Info("goroutine %d message %d", g, i)
+				} else {
+					handler.Write([]byte(fmt.Sprintf("goroutine %d message %d\n", g, i)))
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if destination.buffer.String() != copied.String() {
+		t.Fatal("the writers of io.MultiWriter differ")
+	}
+	lines := strings.Split(strings.TrimSuffix(copied.String(), "\n"), "\n")
+	if len(lines) != goroutines*messages {
+		t.Fatalf("%d lines, want %d", len(lines), goroutines*messages)
+	}
+	for _, line := range lines {
+		var g, i int
+		if _, err := fmt.Sscanf(line, "goroutine %d message %d", &g, &i); err != nil || line != fmt.Sprintf("goroutine %d message %d", g, i) {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestReopenThroughWrappers(t *testing.T) {
+	dir := t.TempDir()
+	format := &MessageFormatter{Format: "{{.Message}}\n"}
+	newFile := func(name string) *FileMessageHandler {
+		handler, err := NewFileHandler(filepath.Join(dir, name), DEBUG, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return handler
+	}
+	async, batching, memory := newFile("async.log"), newFile("batching.log"), newFile("memory.log")
+	logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{
+		&AsyncHandler{Handler: async},
+		&BatchingHandler{Target: batching, Formatter: format},
+		&MemoryHandler{Target: memory, FlushLevel: INFO},
+	}}
+	defer logger.Close()
+
+	logger.Info("before")
+	logger.Flush()
+	for _, name := range []string{"async.log", "batching.log", "memory.log"} {
+		if err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, name+".1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after")
+	logger.Flush()
+
+	for _, name := range []string{"async.log", "batching.log", "memory.log"} {
+		for file, want := range map[string]string{name + ".1": "before\n", name: "after\n"} {
+			if data, err := os.ReadFile(filepath.Join(dir, file)); err != nil || string(data) != want {
+				t.Errorf("%s has %q, %v, want %q", file, data, err, want)
+			}
+		}
+	}
+}
+
+func TestMemoryHandlerDefaultFlushLevel(t *testing.T) {
+	logger, buffer := NewTestLogger()
+	logger.Handlers = []MessageHandler{&MemoryHandler{Target: logger.StreamHandler}}
+	logger.StreamHandler = nil
+
+	logger.Warning("kept")
+	if buffer.Len() != 0 {
+		t.Fatalf("WARNING flushed %q, want the records kept until ERROR", buffer.String())
+	}
+	logger.Error("failed")
+	want := "2000-01-01T00:00:00Z WARNING kept\n2000-01-01T00:00:00Z ERROR failed\n"
+	if buffer.String() != want {
+		t.Fatalf("output %q, want %q", buffer.String(), want)
+	}
+}
+
+func TestStreamMessageHandlerColorCached(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+	go io.Copy(io.Discard, reader)
+
+	handler := &StreamMessageHandler{Destination: writer}
+	logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{handler}}
+	logger.Info("pipe")
+	if handler.colorFile != writer || handler.color {
+		t.Fatalf("color of a pipe %v checked for %v, want false for %v", handler.color, handler.colorFile, writer)
+	}
+	handler.SetDestination(new(bytes.Buffer))
+	logger.Info("buffer")
+	if handler.colorFile != writer || handler.color {
+		t.Fatal("a buffer has colors")
+	}
+}
+
+func TestSetLevelWhileHandling(t *testing.T) {
+	stream := &StreamMessageHandler{Destination: io.Discard}
+	async := &AsyncHandler{Handler: &HTTPHandler{URL: "http://127.0.0.1:0", Level: CRITICAL + 1}}
+	defer async.Close()
+	first := &Logger{Level: DEBUG, Handlers: []MessageHandler{stream, async}}
+	second := first.WithField("key", "value")
+
+	var wg sync.WaitGroup
+	for _, logger := range []*Logger{first, second} {
+		wg.Add(1)
+		go func(logger *Logger) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.Info("hello")
+			}
+		}(logger)
+	}
+	for i := 0; i < 100; i++ {
+		stream.SetLevel(MessageLevel(i % 50))
+		async.SetLevel(CRITICAL + 1)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// logging.HTTPHandler, POST every formatted record to URL, like a Slack or
+// any other webhook. Transient failures, network errors and 429 or 5xx
+// responses, are retried up to Retries times, waiting Backoff before the
+// first retry and doubling it after each one. Wrap it in an AsyncHandler so
+// the caller doesn't wait for the network. Formatter defaults to a JSONFormatter,
+// like ContentType.
+type HTTPHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+
+	URL         string
+	Headers     map[string]string
+	ContentType string        // Default: "application/json"
+	Timeout     time.Duration // timeout of every request, Default: 5 seconds
+	Retries     int
+	Backoff     time.Duration // Default: 500 milliseconds
+
+	// WriteTimeout, abandon a message which isn't posted in time, retries and backoff included,
+	// the error wraps context.DeadlineExceeded. Default: 0, no limit but Timeout of every request.
+	WriteTimeout time.Duration
+
+	// Client sends the requests, Default: a client with Timeout, made on the first request.
+	Client *http.Client
+
+	jsonFormat    JSONFormatter
+	defaultClient *http.Client
+	clientOnce    sync.Once
+}
+
+// logging.HTTPHandler.Handle, post the record of logger if it passes level and filter.
+func (handler *HTTPHandler) Handle(logger *Logger) error {
+	formatter := handler.Formatter
+	if formatter == nil {
+		formatter = &handler.jsonFormat
+	}
+	return handle(logger, &handler.Level, handler.Filter, formatter, handler.Write)
+}
+
+// logging.HTTPHandler.SetLevel, set the minimum level of records to post.
+func (handler *HTTPHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.HTTPHandler.Write, post message to URL, retry on transient failures.
+func (handler *HTTPHandler) Write(message []byte) error {
+	backoff := handler.Backoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	ctx := context.Background()
+	if handler.WriteTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, handler.WriteTimeout)
+		defer cancel()
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = handler.post(ctx, message)
+		if err == nil || !retry || attempt >= handler.Retries {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("logging: post to %s: %w", handler.URL, ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// post, send one request, report whether a failure is worth retrying.
+func (handler *HTTPHandler) post(ctx context.Context, message []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", handler.URL, bytes.NewReader(message))
+	if err != nil {
+		return false, err
+	}
+	contentType := handler.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	request.Header.Set("Content-Type", contentType)
+	for key, value := range handler.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := handler.client().Do(request)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("logging: post to %s: %s", handler.URL, response.Status)
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500, err
+}
+
+func (handler *HTTPHandler) client() *http.Client {
+	if handler.Client != nil {
+		return handler.Client
+	}
+	handler.clientOnce.Do(func() {
+		timeout := handler.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		handler.defaultClient = &http.Client{Timeout: timeout}
+	})
+	return handler.defaultClient
+}
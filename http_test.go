@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPHandlerWriteTimeout(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stalled
+	}))
+	defer server.Close()
+	defer close(stalled)
+	handler := &HTTPHandler{URL: server.URL, Retries: 3, WriteTimeout: 200 * time.Millisecond}
+
+	start := time.Now()
+	err := handler.Write([]byte(`{"message":"hello"}`))
+	assertTimeout(t, err, time.Since(start), handler.WriteTimeout)
+}
+
+func TestHTTPHandlerDefaultJSON(t *testing.T) {
+	bodies := make(chan []byte, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type %q, want application/json", r.Header.Get("Content-Type"))
+		}
+		bodies <- body
+	}))
+	defer server.Close()
+	handler := &HTTPHandler{URL: server.URL}
+	logger, _ := NewTestLogger()
+	logger.Handlers = []MessageHandler{handler}
+
+	logger.Error("first")
+	logger.Error("second")
+	for _, want := range []string{"first", "second"} {
+		var record map[string]interface{}
+		body := <-bodies
+		if err := json.Unmarshal(body, &record); err != nil || record["message"] != want {
+			t.Fatalf("posted %q, %v, want JSON with the message %q", body, err, want)
+		}
+	}
+	if handler.client() != handler.client() {
+		t.Fatal("a client per request")
+	}
+}
@@ -0,0 +1,14 @@
+package logging
+
+// logging.Interface, the leveled methods of Logger, for code which takes a
+// logger as a dependency, so it can be replaced in tests, like with logtest.Logger.
+type Interface interface {
+	Debug(format string, a ...interface{})
+	Info(format string, a ...interface{})
+	Notice(format string, a ...interface{})
+	Warning(format string, a ...interface{})
+	Error(format string, a ...interface{})
+	Critical(format string, a ...interface{})
+}
+
+var _ Interface = (*Logger)(nil)
@@ -0,0 +1,224 @@
+//go:build linux
+// +build linux
+
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// logging.JournaldHandler, write records to systemd-journald with its native protocol, so
+// they keep their priority, the level mapped like SyslogHandler does, and their structured
+// fields, named in upper case like REQUEST_ID for "request.id", and FIELD_MESSAGE for a
+// field named like a field of the journal, "message". The caller is CODE_FILE,
+// CODE_LINE and CODE_FUNC. When the journal socket doesn't exist, like out of systemd,
+// records are handled by Fallback instead.
+type JournaldHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter // formats MESSAGE, Default: the message alone, journald records the time.
+
+	// SocketPath, Default: "/run/systemd/journal/socket"
+	SocketPath string
+
+	// Identifier, SYSLOG_IDENTIFIER of every record, Default: the program name
+	Identifier string
+
+	// Fallback, Default: a StreamMessageHandler writing to os.Stderr
+	Fallback MessageHandler
+
+	mutex    sync.Mutex
+	conn     *net.UnixConn
+	fallback MessageHandler
+}
+
+// logging.JournaldHandler.Handle, send the record of logger if it passes level and filter.
+func (handler *JournaldHandler) Handle(logger *Logger) error {
+	formatter := handler.Formatter
+	if formatter == nil {
+		formatter = journalMessageFormatter{}
+	}
+	return handle(logger, &handler.Level, handler.Filter, formatter, func(message []byte) error {
+		return handler.send(logger, handler.entry(logger.Record, message))
+	})
+}
+
+// logging.JournaldHandler.SetLevel, set the minimum level of records to send.
+func (handler *JournaldHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.JournaldHandler.Close, close the connection to the journal.
+func (handler *JournaldHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+	err := handler.conn.Close()
+	handler.conn = nil
+	return err
+}
+
+// entry, return the journal entry of record, whose MESSAGE is message.
+func (handler *JournaldHandler) entry(record *MessageRecord, message []byte) []byte {
+	identifier := handler.Identifier
+	if identifier == "" {
+		identifier = record.Program
+	}
+	buffer := new(bytes.Buffer)
+	writeJournalField(buffer, "MESSAGE", strings.TrimSuffix(string(message), "\n"))
+	writeJournalField(buffer, "PRIORITY", strconv.Itoa(syslogSeverity(record.Level)))
+	writeJournalField(buffer, "SYSLOG_IDENTIFIER", identifier)
+	writeJournalField(buffer, "CODE_FILE", record.LongFileName)
+	writeJournalField(buffer, "CODE_LINE", strconv.Itoa(record.Line))
+	writeJournalField(buffer, "CODE_FUNC", record.FuncName)
+	if record.LoggerName != "" {
+		writeJournalField(buffer, "LOGGER", record.LoggerName)
+	}
+	if record.Stack != "" {
+		writeJournalField(buffer, "STACK", record.Stack)
+	}
+	for key, value := range record.Fields.flat() {
+		writeJournalField(buffer, journalFieldName(key), fmt.Sprint(value))
+	}
+	return buffer.Bytes()
+}
+
+// send, send entry in a datagram, or in a file passed to the journal if it's too big, or
+// pass the record of logger to the fallback if there's no journal.
+func (handler *JournaldHandler) send(logger *Logger, entry []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		conn, err := unboundUnixgram()
+		if err != nil {
+			return err
+		}
+		handler.conn = conn
+	}
+	path := handler.SocketPath
+	if path == "" {
+		path = "/run/systemd/journal/socket"
+	}
+	addr := &net.UnixAddr{Name: path, Net: "unixgram"}
+
+	_, _, err := handler.conn.WriteMsgUnix(entry, nil, addr)
+	switch {
+	case errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED):
+		return handler.fallbackHandler().Handle(logger)
+	case errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS):
+		return handler.sendFile(entry, addr)
+	}
+	return err
+}
+
+// sendFile, write entry to an unlinked temporary file and pass its descriptor to the journal at addr.
+func (handler *JournaldHandler) sendFile(entry []byte, addr *net.UnixAddr) error {
+	dir := "/dev/shm"
+	if _, err := os.Stat(dir); err != nil {
+		dir = os.TempDir()
+	}
+	file, err := os.CreateTemp(dir, "journal.")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	os.Remove(file.Name())
+	if _, err := file.Write(entry); err != nil {
+		return err
+	}
+	_, _, err = handler.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), addr)
+	return err
+}
+
+// unboundUnixgram, return an unconnected datagram socket, which can send file descriptors to any address.
+func unboundUnixgram() (*net.UnixConn, error) {
+	fd, err := syscall.Socket(syscall.AF_UNIX, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	file := os.NewFile(uintptr(fd), "journal")
+	defer file.Close()
+	conn, err := net.FileConn(file)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UnixConn), nil
+}
+
+func (handler *JournaldHandler) fallbackHandler() MessageHandler {
+	if handler.Fallback != nil {
+		return handler.Fallback
+	}
+	if handler.fallback == nil {
+		handler.fallback = &StreamMessageHandler{Destination: os.Stderr}
+	}
+	return handler.fallback
+}
+
+// journalMessageFormatter, the default formatter of JournaldHandler, the message alone.
+type journalMessageFormatter struct{}
+
+func (formatter journalMessageFormatter) GetMessage(logger *Logger) string {
+	return logger.Record.Message
+}
+
+// writeJournalField, write key=value to buffer, or key, the length of value and value if it
+// spans several lines, as the native protocol requires.
+func writeJournalField(buffer *bytes.Buffer, key, value string) {
+	buffer.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		buffer.WriteByte('=')
+		buffer.WriteString(value)
+		buffer.WriteByte('\n')
+		return
+	}
+	buffer.WriteByte('\n')
+	binary.Write(buffer, binary.LittleEndian, uint64(len(value)))
+	buffer.WriteString(value)
+	buffer.WriteByte('\n')
+}
+
+// journalFieldName, return key as a valid journal field name: upper case letters, digits and
+// underscores, not starting with an underscore or a digit, at most 64 bytes. A name of the
+// journal, see journalReservedFields, is prefixed with FIELD_ too, so a field never replaces
+// MESSAGE, PRIORITY, etc.
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	key = strings.TrimLeft(string(name), "_")
+	if key == "" || key[0] >= '0' && key[0] <= '9' || journalReservedFields[key] {
+		key = "FIELD_" + key
+	}
+	if len(key) > 64 {
+		key = key[:64]
+	}
+	return key
+}
+
+// journalReservedFields, the fields JournaldHandler writes and the other ones with a meaning for
+// the journal, see systemd.journal-fields(7). Trusted fields start with an underscore, which
+// journalFieldName removes.
+var journalReservedFields = map[string]bool{
+	"MESSAGE": true, "MESSAGE_ID": true, "PRIORITY": true, "CODE_FILE": true, "CODE_LINE": true,
+	"CODE_FUNC": true, "ERRNO": true, "INVOCATION_ID": true, "USER_INVOCATION_ID": true,
+	"SYSLOG_FACILITY": true, "SYSLOG_IDENTIFIER": true, "SYSLOG_PID": true, "SYSLOG_TIMESTAMP": true,
+	"SYSLOG_RAW": true, "DOCUMENTATION": true, "TID": true, "UNIT": true, "USER_UNIT": true,
+	"LOGGER": true, "STACK": true,
+}
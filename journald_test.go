@@ -0,0 +1,37 @@
+//go:build linux
+// +build linux
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJournaldEntryError(t *testing.T) {
+	handler := &JournaldHandler{}
+	record := &MessageRecord{Fields: Fields{"error": newErrorField(fmt.Errorf("wrap: %w", errors.New("boom")))}}
+	entry := string(handler.entry(record, []byte("failed")))
+	for _, want := range []string{"\nERROR=wrap: boom\n", "\nERROR_TYPE=*fmt.wrapError\n", "\nERROR_CHAIN=boom\n"} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("entry %q, want %q", entry, want)
+		}
+	}
+}
+
+func TestJournaldEntryReservedFields(t *testing.T) {
+	handler := &JournaldHandler{Identifier: "app"}
+	record := &MessageRecord{Level: ERROR, Fields: Fields{"message": "user", "priority": "high", "_syslog_identifier": "other", "request.id": 7}}
+	entry := string(handler.entry(record, []byte("failed")))
+	for _, want := range []string{"MESSAGE=failed\n", "\nPRIORITY=3\n", "\nSYSLOG_IDENTIFIER=app\n", "\nFIELD_MESSAGE=user\n",
+		"\nFIELD_PRIORITY=high\n", "\nFIELD_SYSLOG_IDENTIFIER=other\n", "\nREQUEST_ID=7\n"} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("entry %q, want %q", entry, want)
+		}
+	}
+	if strings.Count(entry, "\nPRIORITY=") != 1 || strings.Count(entry, "MESSAGE=") != 2 {
+		t.Errorf("entry %q has duplicate reserved fields", entry)
+	}
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxLevel, above every level.
+const maxLevel = MessageLevel(int(^uint(0) >> 1))
+
+// logging.LevelFile, a file of NewLeveledFileHandlers and the range of levels written
+// to it, Max 0 means no upper bound.
+type LevelFile struct {
+	Name     string
+	Min, Max MessageLevel
+}
+
+// logging.NewLeveledFileHandlers, return a FileMessageHandler per file in dir,
+// filtered by a LevelRangeFilter of its levels, ready to assign to Logger.Handlers.
+// Without files, it's app.log for every record and error.log for ERROR and above.
+// dir is created if it's missing, the files are opened in append mode.
+func NewLeveledFileHandlers(dir string, formatter Formatter, files ...LevelFile) ([]MessageHandler, error) {
+	if len(files) == 0 {
+		files = []LevelFile{{Name: "app.log"}, {Name: "error.log", Min: ERROR}}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	handlers := make([]MessageHandler, 0, len(files))
+	for _, levelFile := range files {
+		file, _, err := openLogFile(filepath.Join(dir, levelFile.Name))
+		if err != nil {
+			for _, handler := range handlers {
+				closeHandler(handler)
+			}
+			return nil, err
+		}
+		max := levelFile.Max
+		if max == NOTSET {
+			max = maxLevel
+		}
+		handlers = append(handlers, &FileMessageHandler{
+			Level:       levelFile.Min,
+			Filter:      LevelRangeFilter(levelFile.Min, max),
+			Formatter:   formatter,
+			Destination: file,
+		})
+	}
+	return handlers, nil
+}
+
+// logging.LevelRoute, a destination of LevelRoutingHandler and the range of levels written
+// to it, Max 0 means no upper bound.
+type LevelRoute struct {
+	Min, Max    MessageLevel
+	Destination io.Writer
+}
+
+// logging.LevelRoutingHandler, write every record to the destination of the first route
+// whose range has its level, records of no route are dropped. Without routes, it's
+// os.Stdout below WARNING and os.Stderr from WARNING, the Unix convention. Colors are
+// handled per destination like StreamMessageHandler does.
+type LevelRoutingHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+	Routes    []LevelRoute
+	NoColor   bool
+
+	once    sync.Once
+	streams []*StreamMessageHandler // a handler per route.
+	ranges  [][2]MessageLevel
+}
+
+// logging.LevelRoutingHandler.Handle, write the record of logger to its route if it passes level and filter.
+func (handler *LevelRoutingHandler) Handle(logger *Logger) error {
+	if logger.Record.Level < loadLevel(&handler.Level) || (handler.Filter != nil && !handler.Filter(logger)) {
+		return nil
+	}
+	handler.once.Do(handler.init)
+	for i, levels := range handler.ranges {
+		if logger.Record.Level >= levels[0] && logger.Record.Level <= levels[1] {
+			return handler.streams[i].Handle(logger)
+		}
+	}
+	return nil
+}
+
+// logging.LevelRoutingHandler.SetLevel, set the minimum level of records to write.
+func (handler *LevelRoutingHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+func (handler *LevelRoutingHandler) init() {
+	routes := handler.Routes
+	if len(routes) == 0 {
+		routes = []LevelRoute{{Max: WARNING - 1, Destination: os.Stdout}, {Min: WARNING, Destination: os.Stderr}}
+	}
+	for _, route := range routes {
+		stream := &StreamMessageHandler{Formatter: handler.Formatter, Destination: route.Destination, NoColor: handler.NoColor}
+		max := route.Max
+		if max == NOTSET {
+			max = maxLevel
+		}
+		handler.streams = append(handler.streams, stream)
+		handler.ranges = append(handler.ranges, [2]MessageLevel{route.Min, max})
+	}
+}
@@ -6,8 +6,15 @@ Every MessageHandler can have different MessageFormatter and MessageFilter.
 package logging
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type MessageLevel int
@@ -28,7 +35,7 @@ const (
 const LevelColorSeqClear = "\033[0m"
 
 // LevelColorFlag, MessageLevel color flag.
-var LevelColorFlag = []string{
+var LevelColorFlag = map[MessageLevel]string{
 	DEBUG:    levelColorSeq(ColorBlue, 0),
 	INFO:     levelColorSeq(ColorGreen, 0),
 	NOTICE:   levelColorSeq(ColorWhite, 0),
@@ -58,19 +65,125 @@ const (
 	CRITICAL = MessageLevel(10 * iota) // CRITICAL = 60
 )
 
+// logging.ParseLevel, return the MessageLevel of a case-insensitive level name
+// like "debug" or "WARNING", or of its numeric form like "10".
+func ParseLevel(s string) (MessageLevel, error) {
+	levelMutex.RLock()
+	defer levelMutex.RUnlock()
+
+	name := strings.TrimSpace(s)
+	if number, err := strconv.Atoi(name); err == nil {
+		if _, ok := LevelString[MessageLevel(number)]; ok {
+			return MessageLevel(number), nil
+		}
+		return NOTSET, fmt.Errorf("logging: unknown level %q", s)
+	}
+	for level, levelString := range LevelString {
+		if strings.EqualFold(levelString, name) {
+			return level, nil
+		}
+	}
+	return NOTSET, fmt.Errorf("logging: unknown level %q", s)
+}
+
+// levelMutex, guard LevelString and LevelColorFlag, levels can be registered at any time.
+var levelMutex sync.RWMutex
+
+// logging.RegisterLevel, add a custom level named name, like TRACE below DEBUG,
+// so it can be parsed, formatted and recorded with Logger.Log. Its color is
+// white unless it has one already.
+func RegisterLevel(level MessageLevel, name string) {
+	levelMutex.Lock()
+	defer levelMutex.Unlock()
+
+	LevelString[level] = name
+	if _, ok := LevelColorFlag[level]; !ok {
+		LevelColorFlag[level] = levelColorSeq(ColorWhite, 0)
+	}
+}
+
+// logging.SetLevelColor, set the color of level, like ColorBlue, in bold if bold is set, for the
+// records made from now on. It applies to custom levels too.
+func SetLevelColor(level MessageLevel, color MessageLevel, bold bool) {
+	levelMutex.Lock()
+	defer levelMutex.Unlock()
+
+	way := 0
+	if bold {
+		way = 1
+	}
+	LevelColorFlag[level] = levelColorSeq(color, way)
+}
+
+// levelInfo, return the string and the color flag of level.
+func levelInfo(level MessageLevel) (string, string) {
+	levelMutex.RLock()
+	defer levelMutex.RUnlock()
+
+	return LevelString[level], LevelColorFlag[level]
+}
+
 func levelColorSeq(l MessageLevel, way int) string {
 	return fmt.Sprintf("\033[%d;%dm", way, MessageLevel(l))
 }
 
+// logging.Clock, tell the time of the records of a logger.
+type Clock interface {
+	Now() time.Time
+}
+
+// logging.FixedClock, a Clock always telling the same time, for tests comparing output.
+type FixedClock time.Time
+
+// logging.FixedClock.Now, return the time of clock.
+func (clock FixedClock) Now() time.Time {
+	return time.Time(clock)
+}
+
 // Logger, define logger entity.
 type Logger struct {
-	Level         MessageLevel          // continue only message level gte Level
-	Filter        MessageFilter         // logger message filter, you can define it as your will.
-	Record        *MessageRecord        // message entity, you must not instance it.
-	StreamHandler *StreamMessageHandler // StreamMessageHandler
-	FileHandler   *FileMessageHandler   // FileMessageHandler
+	Level            MessageLevel          // continue only message level gte Level
+	Filter           MessageFilter         // logger message filter, you can define it as your will.
+	Record           *MessageRecord        // internal, the record being handled, for filters, hooks and handlers only, reused once they return, see MessageRecord and LastRecord.
+	StreamHandler    *StreamMessageHandler // StreamMessageHandler
+	FileHandler      *FileMessageHandler   // FileMessageHandler
+	Handlers         []MessageHandler      // any other handlers, each one applies its own Level and Filter.
+	ErrorHandler     func(err error)       // called when a handler fails to write, after the lock is released, so it may log, Default: print to stderr.
+	Fields           Fields                // structured fields attached to every record, see WithField.
+	defaultFields    Fields                // fields of SetDefaultFields, under the ones of the record.
+	CallerSkip       int                   // more frames to skip for the caller, 0 means the direct caller of Debug, Info, etc.
+	DefaultFormatter Formatter             // formats for handlers without Formatter, Default: a MessageFormatter with DefaultFormat.
+	Processors       []Processor           // change or drop records passing Filter, in order before hooks, see Processor.
+	Hooks            []MessageHook         // called in order before handlers, under the lock, a panic in a hook goes to ErrorHandler.
+	CaptureStack     MessageLevel          // records of this level or above have a Stack, Default: NOTSET, never.
+	Name             string                // dotted name of a logger of GetLogger, like "app.db".
+	Propagate        bool                  // pass records to the handlers of the ancestors too, see GetLogger.
+	parent           *Logger               // nearest ancestor of a logger of GetLogger.
+	topLevel         bool                  // a logger of GetLogger without a dot, its parent is Default().
+	ctx              context.Context       // context of WithContext, passed to handlers in MessageRecord.Context.
+	mutex            sync.Mutex            // guard Record, it's set, filtered and written atomically per call.
+	History          int                   // LastRecords keeps this many records, Default: 0, only the last one.
+	GoroutineID      bool                  // records have the GoroutineID of the caller, for debugging, Default: false.
+	Elapsed          ElapsedMode           // records have an Elapsed duration, for quick profiling, Default: ElapsedNone.
+	Clock            Clock                 // time of the records, like a FixedClock in tests, Default: time.Now.
+	sighup           sync.Once             // HandleSIGHUP installs its signal handler once.
+	stats            *loggerStats          // counters of dropped records and time of the last one, shared with the clones of l.
+	last             MessageRecord         // copy of the last record handled.
+	history          []MessageRecord       // ring buffer of the last History records, the oldest one is at historyStart.
+	historyStart     int
+	dispatching      bool    // dispatch is running, handleError keeps the errors in errs.
+	errs             []error // errors of dispatch, reported by unlock.
 }
 
+// DefaultFormat, DefaultTimeFormat, format of the default logger.
+const (
+	DefaultFormat     = `{{.Color}}[{{.Time}}] {{.LevelString | printf "%8s"}}  {{.FuncName}} {{.ShortFileName}} {{.Line}} {{.ColorClear}} {{.Message}}`
+	DefaultTimeFormat = "2006-01-02 15:04:05"
+)
+
+// defaultFormatter, used by handlers when neither they nor their logger have a formatter.
+var defaultFormatter = &MessageFormatter{Format: DefaultFormat, TimeFormat: DefaultTimeFormat}
+
 // logging.GetDefaultLogger, return a default logger object.
 func GetDefaultLogger() *Logger {
 
@@ -79,65 +192,832 @@ func GetDefaultLogger() *Logger {
 		StreamHandler: &StreamMessageHandler{
 			Level: DEBUG,
 			Formatter: &MessageFormatter{
-				Format:     `{{.Color}}[{{.Time}}] {{.LevelString | printf "%8s"}}  {{.FuncName}} {{.ShortFileName}} {{.Line}} {{.ColorClear}} {{.Message}}`,
-				TimeFormat: "2006-01-02 15:04:05",
+				Format:     DefaultFormat,
+				TimeFormat: DefaultTimeFormat,
 			},
 			Destination: os.Stdout,
 		},
 	}
 }
 
-// Logger.Log, sed message to different handler.
+// logging.GetDiscardLogger, return a logger discarding every message, useful in tests.
+func GetDiscardLogger() *Logger {
+	return &Logger{
+		Level:    DEBUG,
+		Handlers: []MessageHandler{NullHandler{}},
+	}
+}
+
+// Logger.Log, sed message to different handler, format is parsed only with arguments.
 func (l *Logger) log(level MessageLevel, format string, a ...interface{}) {
+	l.output(level, len(a) > 0, format, a...)
+}
+
+// Logger.output, sed message to different handler, format is parsed if parse is set.
+// Nothing is allocated below the logger level.
+func (l *Logger) output(level MessageLevel, parse bool, format string, a ...interface{}) {
+	l.emit(nil, level, func() string { return sprintf(parse, format, a...) }, a)
+}
+
+// Logger.outputContext, like output, the record carries ctx, if it's not nil, and the fields found
+// in it like by WithContext.
+func (l *Logger) outputContext(ctx context.Context, level MessageLevel, parse bool, format string, a ...interface{}) {
+	l.emit(ctx, level, func() string { return sprintf(parse, format, a...) }, a)
+}
+
+// Logger.outputMessage, like output, message is never parsed, it's written as it is.
+func (l *Logger) outputMessage(level MessageLevel, message string) {
+	l.emit(nil, level, func() string { return message }, nil)
+}
 
-	if level >= l.Level {
+// Logger.emit, make the record of level, whose message is returned by message, called only
+// if level passes, and the errors are the ones of a, then pass it to dispatch.
+func (l *Logger) emit(ctx context.Context, level MessageLevel, message func() string, a []interface{}) {
 
-		l.Record = GetMessageRecord(level, format, a...)
+	l.mutex.Lock()
+	defer l.unlock()
+
+	if level < l.effectiveLevel() {
+		l.counters().level.Add(1)
+		return
+	}
+
+	stack := l.CaptureStack > NOTSET && level >= l.CaptureStack
+	l.Record = newMessageRecord(l.CallerSkip, stack, level, message())
+	if len(a) > 0 {
+		l.Record.Errors = errorChain(a)
+	}
+	l.Record.Fields = l.Fields
+	if ctx != nil {
+		l.Record.Context = ctx
+		if fields := contextFields(ctx); len(fields) > 0 {
+			l.Record.Fields = mergeFields(l.Fields, fields)
+		}
+	}
+	if l.Clock != nil {
+		l.Record.Created = l.Clock.Now()
+	}
+	l.dispatch(l.Record)
+}
+
+// Logger.logRecord, dispatch a pooled copy of record if its level passes, for records made
+// outside of the methods of l, like the summaries of DedupFilter.
+func (l *Logger) logRecord(record *MessageRecord) {
+	l.mutex.Lock()
+	defer l.unlock()
+
+	if record.Level < l.effectiveLevel() {
+		l.counters().level.Add(1)
+		return
+	}
+	pooled := recordPool.Get().(*MessageRecord)
+	*pooled = *record
+	l.dispatch(pooled)
+}
 
-		if l.Filter == nil || (l.Filter != nil && l.Filter(l)) {
+// Logger.dispatch, make record the current one and pass it through the filter, the hooks,
+// the handlers and the ancestors of l, then put it back to the pool, handlers keeping
+// it must copy it. The caller holds the lock of l.
+func (l *Logger) dispatch(record *MessageRecord) {
+	l.dispatching = true
+	l.Record = record
+	l.Record.LoggerName = l.Name
+	if record.Context == nil {
+		record.Context = l.ctx
+	}
+	if l.GoroutineID && record.GoroutineID == 0 {
+		record.GoroutineID = goroutineID()
+	}
+	if l.Elapsed != ElapsedNone {
+		record.Elapsed = l.elapsed()
+	}
+	record.Fields = l.withDefaultFields(record.Fields)
+	defer func() {
+		l.Record = nil
+		l.dispatching = false
+		releaseRecord(record)
+	}()
 
-			if l.StreamHandler != nil && level >= l.StreamHandler.Level {
-				if l.StreamHandler.Filter == nil || (l.StreamHandler.Filter != nil && l.StreamHandler.Filter(l)) {
-					l.StreamHandler.Write([]byte(l.StreamHandler.Formatter.GetMessage(l)))
-				}
+	if l.Filter == nil || (l.Filter != nil && l.Filter(l)) {
+		for _, processor := range l.Processors {
+			if l.Record = l.process(processor); l.Record == nil {
+				l.counters().filter.Add(1)
+				return
 			}
+		}
+		for _, hook := range l.Hooks {
+			l.fire(hook)
+		}
+		l.remember(l.Record)
+		for _, handler := range l.handlers() {
+			if err := handler.Handle(l); err != nil {
+				l.handleError(err)
+			}
+		}
+		l.propagate()
+	} else {
+		l.counters().filter.Add(1)
+	}
+}
 
-			if l.FileHandler != nil && level >= l.FileHandler.Level {
-				if l.FileHandler.Filter == nil || (l.FileHandler.Filter != nil && l.FileHandler.Filter(l)) {
-					l.FileHandler.Write([]byte(l.FileHandler.Formatter.GetMessage(l)))
-				}
+// loggerStats, counters of the records dropped by a logger and its clones.
+type loggerStats struct {
+	level    atomic.Uint64
+	filter   atomic.Uint64
+	queue    atomic.Uint64
+	previous atomic.Int64 // time of the last record since processStart, for ElapsedSincePrevious.
+}
+
+// logging.ElapsedMode, what MessageRecord.Elapsed measures.
+type ElapsedMode int
+
+const (
+	ElapsedNone          ElapsedMode = iota // records have no Elapsed.
+	ElapsedSinceStart                       // time since the program started.
+	ElapsedSincePrevious                    // time since the previous record of the logger and its clones, 0 for the first one.
+)
+
+// processStart, when the program started, with a monotonic reading immune to changes of the wall clock.
+var processStart = time.Now()
+
+// Logger.elapsed, return the Elapsed duration of a record made now, from the monotonic clock,
+// not from Created, which is the time of Clock. The caller holds the lock of l.
+func (l *Logger) elapsed() time.Duration {
+	offset := time.Since(processStart)
+	switch l.Elapsed {
+	case ElapsedSinceStart:
+		return offset
+	case ElapsedSincePrevious:
+		if previous := l.counters().previous.Swap(int64(offset)); previous != 0 {
+			return offset - time.Duration(previous)
+		}
+	}
+	return 0
+}
+
+// Logger.counters, return the counters of l, make them on first use. The caller holds the lock of l.
+func (l *Logger) counters() *loggerStats {
+	if l.stats == nil {
+		l.stats = new(loggerStats)
+	}
+	return l.stats
+}
+
+// Logger.DroppedByLevel, return how many records have been below the logger level, of l and
+// of the loggers made from it by WithField, WithFields and WithContext.
+func (l *Logger) DroppedByLevel() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.counters().level.Load()
+}
+
+// Logger.DroppedByFilter, return how many records Filter and Processors have dropped, like a
+// RateLimitFilter or a SamplingFilter, counted like DroppedByLevel. The filters of handlers don't count.
+func (l *Logger) DroppedByFilter() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.counters().filter.Load()
+}
+
+// Logger.DroppedByQueue, return how many records the AsyncHandlers of l have dropped because
+// their queue was full, counted like DroppedByLevel.
+func (l *Logger) DroppedByQueue() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.counters().queue.Load()
+}
+
+// Logger.remember, keep a copy of record for LastRecord and LastRecords. The caller holds the lock of l.
+func (l *Logger) remember(record *MessageRecord) {
+	l.last = *record
+	if l.History <= 0 || len(l.history) > l.History {
+		l.history, l.historyStart = nil, 0
+	}
+	if l.History <= 0 {
+		return
+	}
+	if len(l.history) < l.History {
+		l.history = append(l.history, *record)
+		return
+	}
+	l.history[l.historyStart] = *record
+	l.historyStart = (l.historyStart + 1) % len(l.history)
+}
+
+// Logger.LastRecord, return a copy of the last record l has handled, like for a status page,
+// the zero MessageRecord if there's none. Records of its children aren't included.
+func (l *Logger) LastRecord() MessageRecord {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.last
+}
+
+// Logger.LastRecords, return copies of the last History records l has handled, oldest first.
+func (l *Logger) LastRecords() []MessageRecord {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	records := make([]MessageRecord, 0, len(l.history))
+	records = append(records, l.history[l.historyStart:]...)
+	return append(records, l.history[:l.historyStart]...)
+}
+
+// Logger.ancestor, return the parent of l, Default() for a top-level logger of GetLogger,
+// unless Default() is itself a logger of GetLogger or a clone of one, which would be a cycle.
+func (l *Logger) ancestor() *Logger {
+	if l.parent != nil || !l.topLevel {
+		return l.parent
+	}
+	root := Default()
+	if root == nil || root.topLevel || root.parent != nil {
+		return nil
+	}
+	return root
+}
+
+// Logger.effectiveLevel, return Level, or the level of the nearest ancestor which has
+// one if it's NOTSET. The caller holds the lock of l.
+func (l *Logger) effectiveLevel() MessageLevel {
+	level := l.Level
+	for parent := l.ancestor(); level == NOTSET && parent != nil; parent = parent.ancestor() {
+		parent.mutex.Lock()
+		level = parent.Level
+		parent.mutex.Unlock()
+	}
+	return level
+}
+
+// Logger.propagate, pass the record of l to the handlers of its ancestors, up to the first
+// one which doesn't propagate. Their levels and filters don't apply, only the ones of their
+// handlers. The caller holds the lock of l.
+func (l *Logger) propagate() {
+	propagate := l.Propagate
+	for parent := l.ancestor(); propagate && parent != nil; parent = parent.ancestor() {
+		parent.mutex.Lock()
+		for _, handler := range parent.handlers() {
+			if err := handler.Handle(l); err != nil {
+				l.handleError(err)
 			}
+		}
+		propagate = parent.Propagate
+		parent.mutex.Unlock()
+	}
+}
+
+// Logger.process, return the record passed through processor, or the record unchanged if it
+// panics, the panic goes to handleError.
+func (l *Logger) process(processor Processor) (record *MessageRecord) {
+	record = l.Record
+	defer func() {
+		if r := recover(); r != nil {
+			l.handleError(fmt.Errorf("processor panic: %v", r))
+		}
+	}()
+	return processor(record)
+}
 
+// Logger.fire, call hook, recover a panic and pass it to handleError.
+func (l *Logger) fire(hook MessageHook) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.handleError(fmt.Errorf("hook panic: %v", r))
 		}
+	}()
+	hook(l)
+}
+
+// Logger.handleError, pass err to ErrorHandler, or print it to stderr if there's none. During
+// dispatch, err is kept until unlock, so ErrorHandler never runs under the lock of l.
+func (l *Logger) handleError(err error) {
+	if l.dispatching {
+		l.errs = append(l.errs, err)
+		return
+	}
+	l.reportError(err)
+}
+
+// Logger.unlock, release the lock of l, then report the errors kept during dispatch.
+func (l *Logger) unlock() {
+	errs := l.errs
+	l.errs = nil
+	l.mutex.Unlock()
+	for _, err := range errs {
+		l.reportError(err)
+	}
+}
+
+// Logger.reportError, pass err to ErrorHandler, or print it to stderr if there's none.
+func (l *Logger) reportError(err error) {
+	if l.ErrorHandler != nil {
+		l.ErrorHandler(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "logging: %v\n", err)
+}
+
+// Logger.formatter, return DefaultFormatter, or the built-in formatter if it's nil.
+func (l *Logger) formatter() Formatter {
+	if l.DefaultFormatter != nil {
+		return l.DefaultFormatter
+	}
+	return defaultFormatter
+}
+
+// Logger.handlers, return StreamHandler, FileHandler and Handlers as one slice.
+func (l *Logger) handlers() []MessageHandler {
+	handlers := make([]MessageHandler, 0, len(l.Handlers)+2)
+	if l.StreamHandler != nil {
+		handlers = append(handlers, l.StreamHandler)
+	}
+	if l.FileHandler != nil {
+		handlers = append(handlers, l.FileHandler)
+	}
+	return append(handlers, l.Handlers...)
+}
+
+// Logger.SetLevel, set the level of the logger and of every handler which is a LevelSetter.
+func (l *Logger) SetLevel(level MessageLevel) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.Level = level
+	for _, handler := range l.handlers() {
+		if setter, ok := handler.(LevelSetter); ok {
+			setter.SetLevel(level)
+		}
+	}
+}
+
+// Logger.SetHandlerLevel, set the level of handler, which should be attached to the logger.
+func (l *Logger) SetHandlerLevel(handler LevelSetter, level MessageLevel) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	handler.SetLevel(level)
+}
+
+// Logger.WithLevel, set the level of the logger and return a function restoring the
+// previous one, for a scoped block like defer logger.WithLevel(DEBUG)(). Handlers keep
+// their own level. Scopes nest if they're restored in reverse order.
+func (l *Logger) WithLevel(level MessageLevel) (restore func()) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	previous := l.Level
+	l.Level = level
+	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		l.Level = previous
 	}
 }
 
-// Logger.Debug, record DEBUG message.
+// Logger.IsEnabled, report whether records of level pass the logger level, to guard
+// the construction of expensive arguments.
+func (l *Logger) IsEnabled(level MessageLevel) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return level >= l.effectiveLevel()
+}
+
+// Logger.IsDebug, report whether DEBUG records pass the logger level.
+func (l *Logger) IsDebug() bool {
+	return l.IsEnabled(DEBUG)
+}
+
+// Logger.IsInfo, report whether INFO records pass the logger level.
+func (l *Logger) IsInfo() bool {
+	return l.IsEnabled(INFO)
+}
+
+// Logger.IsNotice, report whether NOTICE records pass the logger level.
+func (l *Logger) IsNotice() bool {
+	return l.IsEnabled(NOTICE)
+}
+
+// Logger.IsWarning, report whether WARNING records pass the logger level.
+func (l *Logger) IsWarning() bool {
+	return l.IsEnabled(WARNING)
+}
+
+// Logger.IsError, report whether ERROR records pass the logger level.
+func (l *Logger) IsError() bool {
+	return l.IsEnabled(ERROR)
+}
+
+// Logger.IsCritical, report whether CRITICAL records pass the logger level.
+func (l *Logger) IsCritical() bool {
+	return l.IsEnabled(CRITICAL)
+}
+
+// Logger.GetLevel, return the level of the logger.
+func (l *Logger) GetLevel() MessageLevel {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.Level
+}
+
+// Logger.Flush, write the records buffered by every handler which is a Flusher.
+func (l *Logger) Flush() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var errs []error
+	for _, handler := range l.handlers() {
+		if flusher, ok := handler.(Flusher); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Logger.Reopen, reopen the files of every handler which is a Reopener, like after they
+// have been moved by logrotate, see HandleSIGHUP.
+func (l *Logger) Reopen() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var errs []error
+	for _, handler := range l.handlers() {
+		if reopener, ok := handler.(Reopener); ok {
+			errs = append(errs, reopener.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Logger.Close, flush and close every handler, a handler which is a Closer is
+// closed, the others are flushed if they're a Flusher. StreamHandler is never
+// closed, so os.Stdout stays open. Call it at shutdown, usually with
+// defer logger.Close() in main, so buffered records aren't lost.
+func (l *Logger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var errs []error
+	for _, handler := range l.handlers() {
+		errs = append(errs, closeHandler(handler))
+	}
+	return errors.Join(errs...)
+}
+
+// Logger.WithField, return a logger sharing the handlers of l, whose records carry key
+// and all the fields of l.
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+// Logger.WithFields, return a logger sharing the handlers of l, whose records carry fields
+// and all the fields of l. fields takes precedence over the fields of l on key collision.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	child := l.clone()
+	child.Fields = mergeFields(child.Fields, fields)
+	return child
+}
+
+// mergeFields, return a new map of the fields of base and fields, fields takes precedence on key collision.
+func mergeFields(base Fields, fields map[string]interface{}) Fields {
+	merged := make(Fields, len(base)+len(fields))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Logger.WithError, return a logger like WithField whose records carry err in the field "error",
+// an ErrorField with its message, its type and the errors it wraps, or l itself if err is nil.
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l
+	}
+	return l.WithFields(Fields{"error": newErrorField(err)})
+}
+
+// Logger.SetDefaultFields, set the fields attached to every record of l and of the loggers
+// made from it afterwards, like the service name, version or hostname. The fields of
+// WithField take precedence on key collision.
+func (l *Logger) SetDefaultFields(fields map[string]interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	defaults := make(Fields, len(fields))
+	for key, value := range fields {
+		defaults[key] = value
+	}
+	l.defaultFields = defaults
+}
+
+// Logger.withDefaultFields, return fields merged over the default fields of l. The caller holds the lock of l.
+func (l *Logger) withDefaultFields(fields Fields) Fields {
+	if len(l.defaultFields) == 0 {
+		return fields
+	}
+	if len(fields) == 0 {
+		return l.defaultFields
+	}
+	return mergeFields(l.defaultFields, fields)
+}
+
+// Logger.Reset, restore l to its zero state but for its outputs, like between tests sharing it.
+// Level, Filter, Fields, the fields of SetDefaultFields, Processors, Hooks, CaptureStack,
+// CallerSkip, History and the records it kept, GoroutineID, Elapsed, Clock and the counters of
+// dropped records are reset. StreamHandler, FileHandler, Handlers, ErrorHandler, DefaultFormatter,
+// Name and Propagate are kept, like the levels of the handlers and the context of WithContext.
+func (l *Logger) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.Level = NOTSET
+	l.Filter = nil
+	l.Fields = nil
+	l.defaultFields = nil
+	l.Processors = nil
+	l.Hooks = nil
+	l.CaptureStack = NOTSET
+	l.CallerSkip = 0
+	l.History = 0
+	l.last = MessageRecord{}
+	l.history = nil
+	l.historyStart = 0
+	l.GoroutineID = false
+	l.Elapsed = ElapsedNone
+	l.Clock = nil
+	l.stats = nil
+}
+
+// Logger.clone, return a new logger with the same configuration as l.
+func (l *Logger) clone() *Logger {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	stats := l.counters()
+	return &Logger{
+		Level:            l.Level,
+		Filter:           l.Filter,
+		StreamHandler:    l.StreamHandler,
+		FileHandler:      l.FileHandler,
+		Handlers:         l.Handlers,
+		ErrorHandler:     l.ErrorHandler,
+		Fields:           l.Fields,
+		defaultFields:    l.defaultFields,
+		CallerSkip:       l.CallerSkip,
+		DefaultFormatter: l.DefaultFormatter,
+		Processors:       l.Processors,
+		Hooks:            l.Hooks,
+		CaptureStack:     l.CaptureStack,
+		Name:             l.Name,
+		Propagate:        l.Propagate,
+		History:          l.History,
+		GoroutineID:      l.GoroutineID,
+		Elapsed:          l.Elapsed,
+		Clock:            l.Clock,
+		parent:           l.parent,
+		topLevel:         l.topLevel,
+		ctx:              l.ctx,
+		stats:            stats,
+	}
+}
+
+// Logger.Log, record message of any level, like the ones added by RegisterLevel,
+// format is the literal message if there are no arguments.
+func (l *Logger) Log(level MessageLevel, format string, a ...interface{}) {
+	l.log(level, format, a...)
+}
+
+// Logger.Logf, record message of any level, format is always parsed, and []byte arguments
+// printed with %s or %v are written as they are, not as a list of numbers, even if they
+// aren't valid UTF-8, like a dump of a binary protocol.
+func (l *Logger) Logf(level MessageLevel, format string, a ...interface{}) {
+	args := make([]interface{}, len(a))
+	for i, arg := range a {
+		if b, ok := arg.([]byte); ok {
+			arg = rawBytes(b)
+		}
+		args[i] = arg
+	}
+	l.output(level, true, format, args...)
+}
+
+// Logger.LogBytes, record message of any level byte for byte, without formatting, like LogLiteral.
+func (l *Logger) LogBytes(level MessageLevel, message []byte) {
+	l.outputMessage(level, string(message))
+}
+
+// rawBytes, []byte written as they are by %s and %v, formatted like []byte by other verbs.
+type rawBytes []byte
+
+func (b rawBytes) Format(state fmt.State, verb rune) {
+	if verb == 'v' && !state.Flag('#') {
+		verb = 's'
+	}
+	fmt.Fprintf(state, fmt.FormatString(state, verb), []byte(b))
+}
+
+// Logger.Debug, record DEBUG message, format is the literal message if there are no arguments.
 func (l *Logger) Debug(format string, a ...interface{}) {
 	l.log(DEBUG, format, a...)
 }
 
-// Logger.Info, record INFO message.
+// Logger.Info, record INFO message, format is the literal message if there are no arguments.
 func (l *Logger) Info(format string, a ...interface{}) {
 	l.log(INFO, format, a...)
 }
 
-// Logger.Notice, record INFO message.
+// Logger.Notice, record NOTICE message, format is the literal message if there are no arguments.
 func (l *Logger) Notice(format string, a ...interface{}) {
 	l.log(NOTICE, format, a...)
 }
 
-// Logger.Warning, record WARNING message.
+// Logger.Warning, record WARNING message, format is the literal message if there are no arguments.
 func (l *Logger) Warning(format string, a ...interface{}) {
 	l.log(WARNING, format, a...)
 }
 
-// Logger.Error, record ERROR message.
+// Logger.Error, record ERROR message, format is the literal message if there are no arguments.
 func (l *Logger) Error(format string, a ...interface{}) {
 	l.log(ERROR, format, a...)
 }
 
-// Logger.Critical, record CRITICAL message.
+// Logger.Critical, record CRITICAL message, format is the literal message if there are no arguments.
 func (l *Logger) Critical(format string, a ...interface{}) {
 	l.log(CRITICAL, format, a...)
 }
+
+// Logger.Fatal, record CRITICAL message like Critical, flush every handler so it isn't lost
+// in a buffer, then exit with status 1, like log.Fatal. Deferred calls don't run.
+func (l *Logger) Fatal(format string, a ...interface{}) {
+	l.log(CRITICAL, format, a...)
+	if err := l.Flush(); err != nil {
+		l.reportError(err)
+	}
+	os.Exit(1)
+}
+
+// Logger.Panic, record CRITICAL message like Critical, flush every handler, then panic with
+// the message, like log.Panic.
+func (l *Logger) Panic(format string, a ...interface{}) {
+	l.log(CRITICAL, format, a...)
+	if err := l.Flush(); err != nil {
+		l.reportError(err)
+	}
+	panic(sprintf(len(a) > 0, format, a...))
+}
+
+// Logger.Debugf, record DEBUG message, format is always parsed, so "%%" becomes "%".
+func (l *Logger) Debugf(format string, a ...interface{}) {
+	l.output(DEBUG, true, format, a...)
+}
+
+// Logger.Infof, record INFO message, format is always parsed, so "%%" becomes "%".
+func (l *Logger) Infof(format string, a ...interface{}) {
+	l.output(INFO, true, format, a...)
+}
+
+// Logger.Noticef, record NOTICE message, format is always parsed, so "%%" becomes "%".
+func (l *Logger) Noticef(format string, a ...interface{}) {
+	l.output(NOTICE, true, format, a...)
+}
+
+// Logger.Warningf, record WARNING message, format is always parsed, so "%%" becomes "%".
+func (l *Logger) Warningf(format string, a ...interface{}) {
+	l.output(WARNING, true, format, a...)
+}
+
+// Logger.Errorf, record ERROR message, format is always parsed, so "%%" becomes "%".
+func (l *Logger) Errorf(format string, a ...interface{}) {
+	l.output(ERROR, true, format, a...)
+}
+
+// Logger.Criticalf, record CRITICAL message, format is always parsed, so "%%" becomes "%".
+func (l *Logger) Criticalf(format string, a ...interface{}) {
+	l.output(CRITICAL, true, format, a...)
+}
+
+// Logger.lazy, record the message returned by fn only if level passes the logger level, fn is
+// called without the lock of l, so it can log too.
+func (l *Logger) lazy(level MessageLevel, fn func() string) {
+	if l.IsEnabled(level) {
+		l.outputMessage(level, fn())
+		return
+	}
+	l.mutex.Lock()
+	l.counters().level.Add(1)
+	l.mutex.Unlock()
+}
+
+// Logger.DebugLazy, record the message returned by fn at DEBUG level, fn is only called if DEBUG
+// is enabled, so expensive messages cost nothing otherwise:
+//
+//	logger.DebugLazy(func() string { return dump(state) })
+func (l *Logger) DebugLazy(fn func() string) {
+	l.lazy(DEBUG, fn)
+}
+
+// Logger.InfoLazy, like DebugLazy at INFO level.
+func (l *Logger) InfoLazy(fn func() string) {
+	l.lazy(INFO, fn)
+}
+
+// Logger.NoticeLazy, like DebugLazy at NOTICE level.
+func (l *Logger) NoticeLazy(fn func() string) {
+	l.lazy(NOTICE, fn)
+}
+
+// Logger.WarningLazy, like DebugLazy at WARNING level.
+func (l *Logger) WarningLazy(fn func() string) {
+	l.lazy(WARNING, fn)
+}
+
+// Logger.ErrorLazy, like DebugLazy at ERROR level.
+func (l *Logger) ErrorLazy(fn func() string) {
+	l.lazy(ERROR, fn)
+}
+
+// Logger.CriticalLazy, like DebugLazy at CRITICAL level.
+func (l *Logger) CriticalLazy(fn func() string) {
+	l.lazy(CRITICAL, fn)
+}
+
+// Logger.LogLiteral, record message of any level as it is, it's never a format, so text from
+// users or requests can't be read as verbs, like "100%s" becoming "100%!s(MISSING)". Debug,
+// Info, etc. parse their format when there are arguments, and Debugf, Infof, etc. always do,
+// so only constant formats should be passed to them, with untrusted text as an argument.
+func (l *Logger) LogLiteral(level MessageLevel, message string) {
+	l.outputMessage(level, message)
+}
+
+// Logger.DebugLiteral, record message at DEBUG level as it is, see LogLiteral.
+func (l *Logger) DebugLiteral(message string) {
+	l.outputMessage(DEBUG, message)
+}
+
+// Logger.InfoLiteral, record message at INFO level as it is, see LogLiteral.
+func (l *Logger) InfoLiteral(message string) {
+	l.outputMessage(INFO, message)
+}
+
+// Logger.NoticeLiteral, record message at NOTICE level as it is, see LogLiteral.
+func (l *Logger) NoticeLiteral(message string) {
+	l.outputMessage(NOTICE, message)
+}
+
+// Logger.WarningLiteral, record message at WARNING level as it is, see LogLiteral.
+func (l *Logger) WarningLiteral(message string) {
+	l.outputMessage(WARNING, message)
+}
+
+// Logger.ErrorLiteral, record message at ERROR level as it is, see LogLiteral.
+func (l *Logger) ErrorLiteral(message string) {
+	l.outputMessage(ERROR, message)
+}
+
+// Logger.CriticalLiteral, record message at CRITICAL level as it is, see LogLiteral.
+func (l *Logger) CriticalLiteral(message string) {
+	l.outputMessage(CRITICAL, message)
+}
+
+// Logger.LogCtx, record message of any level like Log, with ctx in MessageRecord.Context and
+// the fields found in ctx, set by WithScope or by a ContextExtractor, over the fields of l.
+func (l *Logger) LogCtx(ctx context.Context, level MessageLevel, format string, a ...interface{}) {
+	l.outputContext(ctx, level, len(a) > 0, format, a...)
+}
+
+// Logger.DebugCtx, record DEBUG message with the fields of ctx, see LogCtx.
+func (l *Logger) DebugCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputContext(ctx, DEBUG, len(a) > 0, format, a...)
+}
+
+// Logger.InfoCtx, record INFO message with the fields of ctx, see LogCtx.
+func (l *Logger) InfoCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputContext(ctx, INFO, len(a) > 0, format, a...)
+}
+
+// Logger.NoticeCtx, record NOTICE message with the fields of ctx, see LogCtx.
+func (l *Logger) NoticeCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputContext(ctx, NOTICE, len(a) > 0, format, a...)
+}
+
+// Logger.WarningCtx, record WARNING message with the fields of ctx, see LogCtx.
+func (l *Logger) WarningCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputContext(ctx, WARNING, len(a) > 0, format, a...)
+}
+
+// Logger.ErrorCtx, record ERROR message with the fields of ctx, see LogCtx.
+func (l *Logger) ErrorCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputContext(ctx, ERROR, len(a) > 0, format, a...)
+}
+
+// Logger.CriticalCtx, record CRITICAL message with the fields of ctx, see LogCtx.
+func (l *Logger) CriticalCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputContext(ctx, CRITICAL, len(a) > 0, format, a...)
+}
@@ -0,0 +1,203 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoggerConcurrentRecords(t *testing.T) {
+	const goroutines, messages = 100, 1000
+	logger, buffer := NewTestLogger()
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < messages; i++ {
+				logger.Info("goroutine %d message %d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, goroutines*messages)
+	for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n") {
+		var g, i int
+		if _, err := fmt.Sscanf(line, "2000-01-01T00:00:00Z INFO goroutine %d message %d", &g, &i); err != nil {
+			t.Fatalf("torn line %q: %v", line, err)
+		}
+		if want := fmt.Sprintf("2000-01-01T00:00:00Z INFO goroutine %d message %d", g, i); line != want {
+			t.Fatalf("line %q, want %q", line, want)
+		}
+		if seen[line] {
+			t.Fatalf("duplicate line %q", line)
+		}
+		seen[line] = true
+	}
+	if len(seen) != goroutines*messages {
+		t.Fatalf("%d records, want %d", len(seen), goroutines*messages)
+	}
+}
+
+// withTimeout, fail t if fn doesn't return within a second, like when it deadlocks.
+func withTimeout(t *testing.T, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deadlock")
+	}
+}
+
+func TestErrorHandlerLogs(t *testing.T) {
+	logger, buffer := NewTestLogger()
+	logger.Handlers = []MessageHandler{HandlerFunc(func(logger *Logger) error {
+		if logger.Record.Message == "fail" {
+			return errors.New("write failed")
+		}
+		return nil
+	})}
+	child := logger.WithField("key", "value")
+	logger.ErrorHandler = func(err error) {
+		logger.Error("handler error: %v", err)
+		child.Warning("handler error: %v", err)
+	}
+
+	withTimeout(t, func() { logger.Info("fail") })
+	want := "2000-01-01T00:00:00Z INFO fail\n2000-01-01T00:00:00Z ERROR handler error: write failed\n" +
+		"2000-01-01T00:00:00Z WARNING handler error: write failed key=value\n"
+	if buffer.String() != want {
+		t.Fatalf("output %q, want %q", buffer.String(), want)
+	}
+}
+
+func TestHookPanicWithLoggingErrorHandler(t *testing.T) {
+	logger, buffer := NewTestLogger()
+	audit := logger.WithField("hook", true)
+	logger.Hooks = []MessageHook{
+		func(logger *Logger) {
+			if logger.Record.Message == "relog" {
+				audit.Warning("seen %s", logger.Record.Message)
+			}
+		},
+		func(logger *Logger) {
+			if logger.Record.Message == "panic" {
+				panic("boom")
+			}
+		},
+	}
+	logger.ErrorHandler = func(err error) {
+		logger.Error("%v", err)
+	}
+
+	withTimeout(t, func() {
+		logger.Info("relog")
+		logger.Info("panic")
+	})
+	want := "2000-01-01T00:00:00Z WARNING seen relog hook=true\n" +
+		"2000-01-01T00:00:00Z INFO relog\n" +
+		"2000-01-01T00:00:00Z INFO panic\n" +
+		"2000-01-01T00:00:00Z ERROR hook panic: boom\n"
+	if buffer.String() != want {
+		t.Fatalf("output %q, want %q", buffer.String(), want)
+	}
+}
+
+// BenchmarkInfoLiteral and BenchmarkInfof, a message without arguments isn't parsed by
+// Info, Infof always parses it, compare their allocations.
+func BenchmarkInfoLiteral(b *testing.B) {
+	logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{NullHandler{}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("hello world")
+	}
+}
+
+func BenchmarkInfof(b *testing.B) {
+	logger := &Logger{Level: DEBUG, Handlers: []MessageHandler{NullHandler{}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Infof("hello world")
+	}
+}
+
+// logHelper, log like a helper of the tests, whose caller is reported with CallerSkip 1.
+func logHelper(logger *Logger) {
+	logger.Info("from helper")
+}
+
+func TestCallerFrame(t *testing.T) {
+	logger := GetDiscardLogger()
+	var record MessageRecord
+	logger.Hooks = []MessageHook{func(logger *Logger) { record = *logger.Record }}
+	for _, log := range []func() int{
+		func() int { _, _, line, _ := runtime.Caller(0); logger.Info("info"); return line },
+		func() int { _, _, line, _ := runtime.Caller(0); logger.Infof("infof %d", 1); return line },
+		func() int { _, _, line, _ := runtime.Caller(0); logger.log(INFO, "log"); return line },
+		func() int { _, _, line, _ := runtime.Caller(0); logger.LogLiteral(INFO, "literal"); return line },
+		func() int { _, _, line, _ := runtime.Caller(0); logger.WithField("k", 1).Info("child"); return line },
+	} {
+		line := log()
+		if record.ShortFileName != "logger_test.go" || record.Line != line {
+			t.Errorf("%q reported at %s:%d, want logger_test.go:%d", record.Message, record.ShortFileName, record.Line, line)
+		}
+	}
+
+	logger.CallerSkip = 1
+	_, _, line, _ := runtime.Caller(0)
+	logHelper(logger)
+	if record.ShortFileName != "logger_test.go" || record.Line != line+1 {
+		t.Errorf("helper reported at %s:%d, want logger_test.go:%d", record.ShortFileName, record.Line, line+1)
+	}
+}
+
+func TestDisabledLevelAllocs(t *testing.T) {
+	logger := &Logger{Level: INFO, Handlers: []MessageHandler{NullHandler{}}}
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Debug("disabled %d %s", 42, "x")
+		logger.Debugf("disabled %d", 42)
+		logger.DebugLiteral("disabled")
+	})
+	if allocs != 0 {
+		t.Fatalf("%v allocations per disabled record, want 0", allocs)
+	}
+}
+
+func BenchmarkDebugDisabled(b *testing.B) {
+	logger := &Logger{Level: INFO, Handlers: []MessageHandler{NullHandler{}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Debug("disabled %d %s", 42, "x")
+	}
+}
+
+func TestElapsedWithClock(t *testing.T) {
+	logger, _ := NewTestLogger()
+	var elapsed []time.Duration
+	logger.Hooks = []MessageHook{func(logger *Logger) { elapsed = append(elapsed, logger.Record.Elapsed) }}
+
+	logger.Elapsed = ElapsedSinceStart
+	logger.Info("since start")
+	if elapsed[0] <= 0 || elapsed[0] > time.Since(processStart) {
+		t.Fatalf("elapsed since start %v, want between 0 and %v", elapsed[0], time.Since(processStart))
+	}
+
+	logger.Elapsed = ElapsedSincePrevious
+	logger.Info("first")
+	time.Sleep(10 * time.Millisecond)
+	logger.Info("second")
+	if elapsed[2] < 10*time.Millisecond || elapsed[2] > time.Second {
+		t.Fatalf("elapsed since previous %v, want about 10ms", elapsed[2])
+	}
+}
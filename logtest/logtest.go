@@ -0,0 +1,109 @@
+/*
+Package logtest provides a logging.Interface recording its calls, for tests
+asserting on what the code under test logs.
+
+	logger := &logtest.Logger{}
+	service := NewService(logger)
+	service.Run()
+	if calls := logger.CallsAt(logging.ERROR); len(calls) != 0 {
+		t.Errorf("unexpected errors: %v", calls)
+	}
+*/
+package logtest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gamelife1314/logging"
+)
+
+// logtest.Call, a recorded call of Logger.
+type Call struct {
+	Level  logging.MessageLevel
+	Format string
+	Args   []interface{}
+}
+
+// logtest.Call.Message, return the message of the call, formatted like logging.Logger does.
+func (call Call) Message() string {
+	if len(call.Args) == 0 {
+		return call.Format
+	}
+	return fmt.Sprintf(call.Format, call.Args...)
+}
+
+// logtest.Logger, a logging.Interface recording every call, its zero value is ready to use.
+type Logger struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+var _ logging.Interface = (*Logger)(nil)
+
+// logtest.Logger.Calls, return a copy of the recorded calls, in order.
+func (logger *Logger) Calls() []Call {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	return append([]Call(nil), logger.calls...)
+}
+
+// logtest.Logger.CallsAt, return the recorded calls of level, in order.
+func (logger *Logger) CallsAt(level logging.MessageLevel) []Call {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	var calls []Call
+	for _, call := range logger.calls {
+		if call.Level == level {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// logtest.Logger.Reset, forget the recorded calls.
+func (logger *Logger) Reset() {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	logger.calls = nil
+}
+
+func (logger *Logger) record(level logging.MessageLevel, format string, a []interface{}) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	logger.calls = append(logger.calls, Call{Level: level, Format: format, Args: a})
+}
+
+// logtest.Logger.Debug, record a DEBUG call.
+func (logger *Logger) Debug(format string, a ...interface{}) {
+	logger.record(logging.DEBUG, format, a)
+}
+
+// logtest.Logger.Info, record an INFO call.
+func (logger *Logger) Info(format string, a ...interface{}) {
+	logger.record(logging.INFO, format, a)
+}
+
+// logtest.Logger.Notice, record a NOTICE call.
+func (logger *Logger) Notice(format string, a ...interface{}) {
+	logger.record(logging.NOTICE, format, a)
+}
+
+// logtest.Logger.Warning, record a WARNING call.
+func (logger *Logger) Warning(format string, a ...interface{}) {
+	logger.record(logging.WARNING, format, a)
+}
+
+// logtest.Logger.Error, record an ERROR call.
+func (logger *Logger) Error(format string, a ...interface{}) {
+	logger.record(logging.ERROR, format, a)
+}
+
+// logtest.Logger.Critical, record a CRITICAL call.
+func (logger *Logger) Critical(format string, a ...interface{}) {
+	logger.record(logging.CRITICAL, format, a)
+}
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"errors"
+	"sync"
+)
+
+// logging.MemoryHandler, keep the last Capacity records in memory and write
+// them to Target only when a record of FlushLevel or above arrives, so normal
+// operation stays quiet but failures come with their context. The buffered
+// records are written in order, followed by the triggering one.
+type MemoryHandler struct {
+	Level      MessageLevel
+	Filter     MessageFilter
+	Target     MessageHandler
+	Capacity   int          // Default: 100
+	FlushLevel MessageLevel // Default: ERROR
+
+	mutex   sync.Mutex
+	records []MessageRecord // ring buffer, the oldest record is at start.
+	start   int
+	logger  Logger
+}
+
+// logging.MemoryHandler.Handle, keep the record of logger, flush if its level is FlushLevel or above.
+func (handler *MemoryHandler) Handle(logger *Logger) error {
+	if logger.Record.Level < loadLevel(&handler.Level) || (handler.Filter != nil && !handler.Filter(logger)) {
+		return nil
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.push(*logger.Record)
+	handler.logger.DefaultFormatter = logger.DefaultFormatter
+	if logger.Record.Level >= handler.flushLevel() {
+		return handler.flush()
+	}
+	return nil
+}
+
+func (handler *MemoryHandler) flushLevel() MessageLevel {
+	if handler.FlushLevel == NOTSET {
+		return ERROR
+	}
+	return handler.FlushLevel
+}
+
+// logging.MemoryHandler.SetLevel, set the minimum level of records to keep.
+func (handler *MemoryHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.MemoryHandler.Flush, write the buffered records to Target, then flush it.
+func (handler *MemoryHandler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err := handler.flush()
+	if flusher, ok := handler.Target.(Flusher); ok {
+		err = errors.Join(err, flusher.Flush())
+	}
+	return err
+}
+
+// logging.MemoryHandler.Reopen, reopen Target if it's a Reopener, the buffered records are written
+// to the reopened files when they're flushed.
+func (handler *MemoryHandler) Reopen() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if reopener, ok := handler.Target.(Reopener); ok {
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+// logging.MemoryHandler.Close, write the buffered records to Target, then close it.
+func (handler *MemoryHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return errors.Join(handler.flush(), closeHandler(handler.Target))
+}
+
+// push, append record, overwriting the oldest one when the buffer is full.
+func (handler *MemoryHandler) push(record MessageRecord) {
+	capacity := handler.Capacity
+	if capacity <= 0 {
+		capacity = 100
+	}
+	if len(handler.records) < capacity {
+		handler.records = append(handler.records, record)
+		return
+	}
+	handler.records[handler.start] = record
+	handler.start = (handler.start + 1) % len(handler.records)
+}
+
+// flush, write every buffered record in order and empty the buffer, return the first error.
+func (handler *MemoryHandler) flush() error {
+	var first error
+	for i := range handler.records {
+		record := &handler.records[(handler.start+i)%len(handler.records)]
+		if err := handleRecord(handler.Target, &handler.logger, record); err != nil && first == nil {
+			first = err
+		}
+	}
+	handler.records = handler.records[:0]
+	handler.start = 0
+	return first
+}
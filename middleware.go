@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"errors"
+	"time"
+)
+
+// logging.HandlerFunc, a function used as a MessageHandler, like http.HandlerFunc.
+type HandlerFunc func(logger *Logger) error
+
+// logging.HandlerFunc.Handle, call handler with logger.
+func (handler HandlerFunc) Handle(logger *Logger) error {
+	return handler(logger)
+}
+
+// logging.Middleware, wrap a handler with a behavior, like retrying or timing its writes,
+// see Chain and WrapHandler.
+type Middleware func(handler MessageHandler) MessageHandler
+
+// logging.Chain, return handler wrapped by middlewares, the first one is the outermost,
+// it sees every record first, like with http middleware.
+func Chain(handler MessageHandler, middlewares ...Middleware) MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// logging.WrapHandler, return a handler handling records with handle, and flushing, closing,
+// reopening and setting the level of handler, so a Middleware keeps what handler can do.
+func WrapHandler(handler MessageHandler, handle HandlerFunc) MessageHandler {
+	return &wrappedHandler{handler: handler, handle: handle}
+}
+
+type wrappedHandler struct {
+	handler MessageHandler
+	handle  HandlerFunc
+}
+
+func (wrapped *wrappedHandler) Handle(logger *Logger) error {
+	return wrapped.handle(logger)
+}
+
+func (wrapped *wrappedHandler) SetLevel(level MessageLevel) {
+	if setter, ok := wrapped.handler.(LevelSetter); ok {
+		setter.SetLevel(level)
+	}
+}
+
+func (wrapped *wrappedHandler) Flush() error {
+	if flusher, ok := wrapped.handler.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (wrapped *wrappedHandler) Reopen() error {
+	if reopener, ok := wrapped.handler.(Reopener); ok {
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+func (wrapped *wrappedHandler) Close() error {
+	return closeHandler(wrapped.handler)
+}
+
+// logging.RetryMiddleware, return a middleware handling a record again when the handler fails,
+// at most attempts times in all, waiting backoff before the first retry and twice as long
+// before each next one. The caller waits, wrap it in an AsyncHandler for slow destinations.
+func RetryMiddleware(attempts int, backoff time.Duration) Middleware {
+	return func(handler MessageHandler) MessageHandler {
+		return WrapHandler(handler, func(logger *Logger) error {
+			err := handler.Handle(logger)
+			for attempt, wait := 1, backoff; err != nil && attempt < attempts; attempt, wait = attempt+1, wait*2 {
+				if errors.Is(err, ErrHandlerClosed) {
+					break
+				}
+				time.Sleep(wait)
+				err = handler.Handle(logger)
+			}
+			return err
+		})
+	}
+}
+
+// logging.TimingMiddleware, return a middleware passing how long the handler took to handle
+// every record, and its error, to observe, like to feed a latency histogram.
+func TimingMiddleware(observe func(elapsed time.Duration, err error)) Middleware {
+	return func(handler MessageHandler) MessageHandler {
+		return WrapHandler(handler, func(logger *Logger) error {
+			start := time.Now()
+			err := handler.Handle(logger)
+			observe(time.Since(start), err)
+			return err
+		})
+	}
+}
+
+// logging.AsyncMiddleware, return a middleware wrapping the handler in an AsyncHandler with
+// queueSize and policy.
+func AsyncMiddleware(queueSize int, policy OverflowPolicy) Middleware {
+	return func(handler MessageHandler) MessageHandler {
+		return &AsyncHandler{Handler: handler, QueueSize: queueSize, OverflowPolicy: policy}
+	}
+}
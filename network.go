@@ -0,0 +1,212 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// logging.NetworkHandler, send records over tcp or udp to the first of Endpoints which accepts
+// them: when a write to an endpoint fails, its connection is dropped and the message is sent
+// to the next one, the first one again after the last. While it's on a secondary endpoint,
+// the primary, the first one, is tried every RetryPrimary and used again once it accepts a
+// connection. Over tcp, every message ends with a newline. Over udp, a write only fails when
+// the system reports it, like when the port of a local endpoint is closed.
+type NetworkHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+
+	// Network, "tcp" or "udp", Default: "tcp"
+	Network string
+
+	// Endpoints, addresses like "logs1.example.com:5140", in order of preference.
+	Endpoints []string
+
+	// WriteTimeout, fail over when connecting or writing a message takes longer, the error
+	// wraps context.DeadlineExceeded. Default: 0, no limit
+	WriteTimeout time.Duration
+
+	// RetryPrimary, how often the primary endpoint is tried while on a secondary one.
+	// Default: 30 seconds
+	RetryPrimary time.Duration
+
+	// MaxDatagramSize, over udp, the biggest message, a bigger one is truncated and marked like
+	// "...(truncated, 4096 bytes)", and reported to the ErrorHandler of the logger, instead of
+	// being dropped by the network. Default: 1472 bytes, an Ethernet frame
+	MaxDatagramSize int
+
+	mutex     sync.Mutex
+	conn      net.Conn
+	current   int       // index of the endpoint of conn in Endpoints.
+	lastRetry time.Time // last time the primary was tried.
+}
+
+// logging.NetworkHandler.Handle, send the record of logger if it passes level and filter.
+func (handler *NetworkHandler) Handle(logger *Logger) error {
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, func(message []byte) error {
+		if max := handler.maxDatagramSize(); max > 0 && len(message) > max {
+			logger.handleError(fmt.Errorf("udp message of %d bytes truncated to %d", len(message), max))
+		}
+		return handler.Write(message)
+	})
+}
+
+// logging.NetworkHandler.SetLevel, set the minimum level of records to send.
+func (handler *NetworkHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.NetworkHandler.Write, send message to the current endpoint, or to the next ones until
+// one accepts it, return the errors of all of them if none does.
+func (handler *NetworkHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if len(handler.Endpoints) == 0 {
+		return errors.New("logging: network handler without endpoints")
+	}
+	if handler.network() == "tcp" && (len(message) == 0 || message[len(message)-1] != '\n') {
+		message = append(message[:len(message):len(message)], '\n')
+	}
+	message = truncateDatagram(message, handler.maxDatagramSize())
+	if handler.current >= len(handler.Endpoints) {
+		handler.drop()
+		handler.current = 0
+	}
+	handler.retryPrimary()
+
+	var errs []error
+	for i := 0; i < len(handler.Endpoints); i++ {
+		err := handler.write(message)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", handler.Endpoints[handler.current], err))
+		handler.drop()
+		if handler.current == 0 {
+			handler.lastRetry = time.Now() // the primary just failed, try it again in RetryPrimary.
+		}
+		handler.current = (handler.current + 1) % len(handler.Endpoints)
+	}
+	return fmt.Errorf("logging: every endpoint failed: %w", errors.Join(errs...))
+}
+
+// logging.NetworkHandler.Close, close the connection to the current endpoint.
+func (handler *NetworkHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.conn == nil {
+		return nil
+	}
+	err := handler.conn.Close()
+	handler.conn = nil
+	return err
+}
+
+// maxDatagramSize, return the biggest message over udp, 0 for no limit over tcp.
+func (handler *NetworkHandler) maxDatagramSize() int {
+	switch handler.network() {
+	case "udp", "udp4", "udp6":
+		if handler.MaxDatagramSize > 0 {
+			return handler.MaxDatagramSize
+		}
+		return 1472
+	}
+	return 0
+}
+
+func (handler *NetworkHandler) network() string {
+	if handler.Network == "" {
+		return "tcp"
+	}
+	return handler.Network
+}
+
+// write, write message to the current endpoint, connect first if there's no connection.
+func (handler *NetworkHandler) write(message []byte) error {
+	if handler.conn == nil {
+		conn, err := handler.dial(handler.Endpoints[handler.current])
+		if err != nil {
+			return deadlineError(err)
+		}
+		handler.conn = conn
+	}
+	if handler.WriteTimeout > 0 {
+		handler.conn.SetWriteDeadline(time.Now().Add(handler.WriteTimeout))
+	}
+	_, err := handler.conn.Write(message)
+	return deadlineError(err)
+}
+
+// errWriteTimeout, the error of a write abandoned after its WriteTimeout.
+var errWriteTimeout = fmt.Errorf("%w (%w)", context.DeadlineExceeded, os.ErrDeadlineExceeded)
+
+// deadlineError, return err wrapping context.DeadlineExceeded too if it's a timeout of a
+// deadline, so every WriteTimeout is checked the same way, like with the HTTPHandler.
+func deadlineError(err error) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", err, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// retryPrimary, go back to the primary endpoint if it's time to try it and it accepts a connection.
+func (handler *NetworkHandler) retryPrimary() {
+	if handler.current == 0 {
+		return
+	}
+	interval := handler.RetryPrimary
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if time.Since(handler.lastRetry) < interval {
+		return
+	}
+	handler.lastRetry = time.Now()
+	conn, err := handler.dial(handler.Endpoints[0])
+	if err != nil {
+		return
+	}
+	handler.drop()
+	handler.conn = conn
+	handler.current = 0
+}
+
+func (handler *NetworkHandler) dial(address string) (net.Conn, error) {
+	if handler.WriteTimeout > 0 {
+		return net.DialTimeout(handler.network(), address, handler.WriteTimeout)
+	}
+	return net.Dial(handler.network(), address)
+}
+
+// drop, close the connection, the next write reconnects.
+func (handler *NetworkHandler) drop() {
+	if handler.conn != nil {
+		handler.conn.Close()
+		handler.conn = nil
+	}
+}
+
+// truncateDatagram, return message cut to at most max bytes with its original length, like
+// truncateMessage, or message itself if max is zero or it's short enough.
+func truncateDatagram(message []byte, max int) []byte {
+	if max <= 0 || len(message) <= max {
+		return message
+	}
+	suffix := fmt.Sprintf("...(truncated, %d bytes)", len(message))
+	cut := max - len(suffix)
+	if cut < 0 {
+		return message[:max]
+	}
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return append(message[:cut:cut], suffix...)
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// stalledListener, return the address of a tcp listener which accepts connections but never
+// reads them, so a big enough write blocks, close it with the returned function.
+func stalledListener(t *testing.T) (string, func()) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+	return listener.Addr().String(), func() {
+		close(done)
+		listener.Close()
+	}
+}
+
+// stalledMessage, bigger than the socket buffers, a write of it blocks on a stalled listener.
+var stalledMessage = bytes.Repeat([]byte("x"), 64<<20)
+
+// assertTimeout, fail t unless err is a WriteTimeout returned in about timeout.
+func assertTimeout(t *testing.T, err error, elapsed, timeout time.Duration) {
+	t.Helper()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want one wrapping context.DeadlineExceeded", err)
+	}
+	if elapsed > timeout+time.Second {
+		t.Fatalf("returned after %v, want about %v", elapsed, timeout)
+	}
+}
+
+func TestNetworkHandlerWriteTimeout(t *testing.T) {
+	address, stop := stalledListener(t)
+	defer stop()
+	handler := &NetworkHandler{Endpoints: []string{address}, WriteTimeout: 200 * time.Millisecond}
+	defer handler.Close()
+
+	start := time.Now()
+	err := handler.Write(stalledMessage)
+	assertTimeout(t, err, time.Since(start), handler.WriteTimeout)
+}
@@ -0,0 +1,84 @@
+package logging
+
+// logging.Option, configure a logger made by NewLogger.
+type Option func(logger *Logger)
+
+// logging.NewLogger, return a logger of level DEBUG configured by opts, like:
+//
+//	logger := logging.NewLogger(
+//	    logging.WithLevel(logging.INFO),
+//	    logging.WithStreamHandler(&logging.StreamMessageHandler{Destination: os.Stderr}),
+//	    logging.WithFormatter(&logging.JSONFormatter{}),
+//	)
+//
+// Without a handler option, records go nowhere.
+func NewLogger(opts ...Option) *Logger {
+	logger := &Logger{Level: DEBUG}
+	for _, opt := range opts {
+		opt(logger)
+	}
+	return logger
+}
+
+// logging.WithLevel, set Logger.Level.
+func WithLevel(level MessageLevel) Option {
+	return func(logger *Logger) {
+		logger.Level = level
+	}
+}
+
+// logging.WithStreamHandler, set Logger.StreamHandler.
+func WithStreamHandler(handler *StreamMessageHandler) Option {
+	return func(logger *Logger) {
+		logger.StreamHandler = handler
+	}
+}
+
+// logging.WithFileHandler, set Logger.FileHandler.
+func WithFileHandler(handler *FileMessageHandler) Option {
+	return func(logger *Logger) {
+		logger.FileHandler = handler
+	}
+}
+
+// logging.WithHandler, append handler to Logger.Handlers.
+func WithHandler(handler MessageHandler) Option {
+	return func(logger *Logger) {
+		logger.Handlers = append(logger.Handlers, handler)
+	}
+}
+
+// logging.WithFormatter, set Logger.DefaultFormatter, used by handlers without Formatter.
+func WithFormatter(formatter Formatter) Option {
+	return func(logger *Logger) {
+		logger.DefaultFormatter = formatter
+	}
+}
+
+// logging.WithFilter, set Logger.Filter.
+func WithFilter(filter MessageFilter) Option {
+	return func(logger *Logger) {
+		logger.Filter = filter
+	}
+}
+
+// logging.WithErrorHandler, set Logger.ErrorHandler.
+func WithErrorHandler(errorHandler func(err error)) Option {
+	return func(logger *Logger) {
+		logger.ErrorHandler = errorHandler
+	}
+}
+
+// logging.WithHook, append hook to Logger.Hooks.
+func WithHook(hook MessageHook) Option {
+	return func(logger *Logger) {
+		logger.Hooks = append(logger.Hooks, hook)
+	}
+}
+
+// logging.WithProcessor, append processor to Logger.Processors.
+func WithProcessor(processor Processor) Option {
+	return func(logger *Logger) {
+		logger.Processors = append(logger.Processors, processor)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Package otellog emits the records of a logging.Logger as OpenTelemetry log
+records. It's a separate package, so programs which don't use OpenTelemetry
+don't depend on it.
+
+Export through a batch processor, so records aren't sent one by one:
+
+	exporter, err := otlploghttp.New(ctx)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)))
+	logger.Handlers = append(logger.Handlers, &otellog.Handler{Provider: provider})
+	defer logger.Close()
+
+Records of a logger of logging.Logger.WithContext are emitted with its context, so they're
+correlated with its active span.
+*/
+package otellog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gamelife1314/logging"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// otellog.Handler, a logging.MessageHandler emitting records to an OpenTelemetry logger:
+// the message is the body, the level is the severity, structured fields are attributes, and
+// the caller is code.function.name, code.file.path and code.line.number.
+type Handler struct {
+	Level  logging.MessageLevel
+	Filter logging.MessageFilter
+
+	// Provider of the OpenTelemetry logger, Default: the global one
+	Provider log.LoggerProvider
+
+	// Name of the instrumentation scope, Default: the name of the logger, or "github.com/gamelife1314/logging"
+	Name string
+
+	mutex      sync.Mutex
+	levelMutex sync.RWMutex // guard Level, SetLevel changes it while records are handled.
+	loggers    map[string]log.Logger
+}
+
+// otellog.Handler.Handle, emit the record of logger if it passes level and filter.
+func (handler *Handler) Handle(logger *logging.Logger) error {
+	record := logger.Record
+	if record.Level < handler.level() || (handler.Filter != nil && !handler.Filter(logger)) {
+		return nil
+	}
+
+	var otelRecord log.Record
+	otelRecord.SetTimestamp(record.Created)
+	otelRecord.SetObservedTimestamp(time.Now())
+	otelRecord.SetSeverity(Severity(record.Level))
+	otelRecord.SetSeverityText(record.LevelString)
+	otelRecord.SetBody(log.StringValue(record.Message))
+	otelRecord.AddAttributes(
+		log.String("code.function.name", record.FuncName),
+		log.String("code.file.path", record.LongFileName),
+		log.Int("code.line.number", record.Line),
+	)
+	if record.Stack != "" {
+		otelRecord.AddAttributes(log.String("code.stacktrace", record.Stack))
+	}
+	if len(record.Errors) > 0 {
+		otelRecord.AddAttributes(log.String("exception.message", record.Errors[0]))
+	}
+	for key, value := range record.Fields {
+		otelRecord.AddAttributes(log.KeyValue{Key: key, Value: attributeValue(value)})
+	}
+
+	ctx := record.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	handler.logger(record.LoggerName).Emit(ctx, otelRecord)
+	return nil
+}
+
+// otellog.Handler.SetLevel, set the minimum level of records to emit.
+func (handler *Handler) SetLevel(level logging.MessageLevel) {
+	handler.levelMutex.Lock()
+	defer handler.levelMutex.Unlock()
+
+	handler.Level = level
+}
+
+func (handler *Handler) level() logging.MessageLevel {
+	handler.levelMutex.RLock()
+	defer handler.levelMutex.RUnlock()
+
+	return handler.Level
+}
+
+// otellog.Handler.Flush, export the records batched by Provider, if it can, like the one of the SDK.
+func (handler *Handler) Flush() error {
+	if flusher, ok := handler.provider().(interface{ ForceFlush(context.Context) error }); ok {
+		return flusher.ForceFlush(context.Background())
+	}
+	return nil
+}
+
+// otellog.Handler.Close, export the records batched by Provider and shut it down, if it can.
+func (handler *Handler) Close() error {
+	if shutdowner, ok := handler.provider().(interface{ Shutdown(context.Context) error }); ok {
+		return shutdowner.Shutdown(context.Background())
+	}
+	return handler.Flush()
+}
+
+func (handler *Handler) provider() log.LoggerProvider {
+	if handler.Provider != nil {
+		return handler.Provider
+	}
+	return global.GetLoggerProvider()
+}
+
+// logger, return the OpenTelemetry logger of the scope of a logging.Logger named name.
+func (handler *Handler) logger(name string) log.Logger {
+	if handler.Name != "" {
+		name = handler.Name
+	} else if name == "" {
+		name = "github.com/gamelife1314/logging"
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.loggers == nil {
+		handler.loggers = make(map[string]log.Logger)
+	}
+	logger, ok := handler.loggers[name]
+	if !ok {
+		logger = handler.provider().Logger(name)
+		handler.loggers[name] = logger
+	}
+	return logger
+}
+
+// otellog.Severity, return the OpenTelemetry severity of level, NOTICE is INFO2 and CRITICAL
+// is FATAL, like for syslog.
+func Severity(level logging.MessageLevel) log.Severity {
+	switch {
+	case level >= logging.CRITICAL:
+		return log.SeverityFatal
+	case level >= logging.ERROR:
+		return log.SeverityError
+	case level >= logging.WARNING:
+		return log.SeverityWarn
+	case level >= logging.NOTICE:
+		return log.SeverityInfo2
+	case level >= logging.INFO:
+		return log.SeverityInfo
+	case level >= logging.DEBUG:
+		return log.SeverityDebug
+	}
+	return log.SeverityTrace
+}
+
+// attributeValue, return value as an attribute value of its kind, or as its default format.
+func attributeValue(value interface{}) log.Value {
+	switch v := value.(type) {
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint32:
+		return log.Int64Value(int64(v))
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case []byte:
+		return log.BytesValue(v)
+	case error:
+		return log.StringValue(v.Error())
+	case fmt.Stringer:
+		return log.StringValue(v.String())
+	}
+	return log.StringValue(fmt.Sprint(value))
+}
@@ -0,0 +1,47 @@
+/*
+Package promlog counts the records of a logging.Logger per level with a
+Prometheus counter. It's a separate package, so programs which don't use
+Prometheus don't depend on it.
+
+	collector := promlog.NewCollector("myapp")
+	prometheus.MustRegister(collector)
+	logger.Hooks = append(logger.Hooks, collector.Hook)
+*/
+package promlog
+
+import (
+	"github.com/gamelife1314/logging"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// promlog.Collector, a prometheus.Collector of the records per level, named
+// <namespace>_log_records_total with a "level" label.
+type Collector struct {
+	records *prometheus.CounterVec
+}
+
+// promlog.NewCollector, return a collector whose counter is in namespace.
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		records: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "log_records_total",
+			Help:      "Number of log records per level.",
+		}, []string{"level"}),
+	}
+}
+
+// promlog.Collector.Hook, a logging.MessageHook counting the record of logger.
+func (collector *Collector) Hook(logger *logging.Logger) {
+	collector.records.WithLabelValues(logger.Record.LevelString).Inc()
+}
+
+// promlog.Collector.Describe, implement prometheus.Collector.
+func (collector *Collector) Describe(descs chan<- *prometheus.Desc) {
+	collector.records.Describe(descs)
+}
+
+// promlog.Collector.Collect, implement prometheus.Collector.
+func (collector *Collector) Collect(metrics chan<- prometheus.Metric) {
+	collector.records.Collect(metrics)
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// logging.RateLimitFilter, limit records of the levels in Rates to that many
+// per second with a token bucket of Burst tokens, the records above the rate
+// are dropped. Levels missing from Rates aren't limited. Assign its Filter
+// method to a Filter field:
+//
+//	limiter := &logging.RateLimitFilter{Rates: map[logging.MessageLevel]float64{logging.WARNING: 10}}
+//	handler.Filter = limiter.Filter
+type RateLimitFilter struct {
+	Rates map[MessageLevel]float64
+
+	// Burst, records allowed at once, Default: the rate rounded up.
+	Burst int
+
+	mutex   sync.Mutex
+	buckets map[MessageLevel]*tokenBucket
+	dropped uint64
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// logging.RateLimitFilter.Filter, report whether the bucket of the record level has a token left.
+func (filter *RateLimitFilter) Filter(logger *Logger) bool {
+	level := logger.Record.Level
+	rate, ok := filter.Rates[level]
+	if !ok {
+		return true
+	}
+
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	burst := float64(filter.Burst)
+	if burst <= 0 {
+		burst = math.Max(math.Ceil(rate), 1)
+	}
+	now := time.Now()
+	if filter.buckets == nil {
+		filter.buckets = make(map[MessageLevel]*tokenBucket)
+	}
+	bucket, ok := filter.buckets[level]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		filter.buckets[level] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		filter.dropped++
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// logging.RateLimitFilter.Dropped, return how many records have been dropped.
+func (filter *RateLimitFilter) Dropped() uint64 {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	return filter.dropped
+}
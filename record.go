@@ -1,13 +1,132 @@
 package logging
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// logging.MessageRecord
+// logging.Fields, structured key-value fields attached to records.
+type Fields map[string]interface{}
+
+// logging.Fields.String, render fields as space separated key=value pairs, in no particular order.
+func (fields Fields) String() string {
+	return fields.format(false)
+}
+
+// Fields.format, render fields as space separated key=value pairs, sorted by key if sorted is set.
+func (fields Fields) format(sorted bool) string {
+	fields = fields.flat()
+	buffer := new(bytes.Buffer)
+	for _, key := range fields.keys(sorted) {
+		if buffer.Len() > 0 {
+			buffer.WriteByte(' ')
+		}
+		fmt.Fprintf(buffer, "%s=%v", key, fields[key])
+	}
+	return buffer.String()
+}
+
+// Fields.flat, return fields with every ErrorField, like the one of Logger.WithError, spread over
+// the fields key, its message, key_type, its type, and key_chain, the messages it wraps, for
+// the formats without nested values. Fields set by the caller win, fields itself is returned
+// if it has no ErrorField.
+func (fields Fields) flat() Fields {
+	found := false
+	for _, value := range fields {
+		if _, ok := value.(ErrorField); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fields
+	}
+	flat := make(Fields, len(fields)+2)
+	for key, value := range fields {
+		flat[key] = value
+	}
+	for key, value := range fields {
+		field, ok := value.(ErrorField)
+		if !ok {
+			continue
+		}
+		flat[key] = field.Message
+		if _, ok := fields[key+"_type"]; !ok {
+			flat[key+"_type"] = field.Type
+		}
+		if _, ok := fields[key+"_chain"]; !ok && len(field.Chain) > 0 {
+			flat[key+"_chain"] = strings.Join(field.Chain, "; ")
+		}
+	}
+	return flat
+}
+
+// Fields.keys, return the keys of fields, sorted if sorted is set.
+func (fields Fields) keys(sorted bool) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	if sorted {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// logging.ErrorField, the "error" field of Logger.WithError, written as {"message":..., "type":...,
+// "chain":[...]} by JSONFormatter, where type is the concrete type of the error, like "*fs.PathError",
+// and chain the messages of the errors it wraps, if there're some. The other formatters and
+// handlers, text, logfmt, GELF and journald, write the fields error, error_type and error_chain.
+type ErrorField struct {
+	Message string   `json:"message"`
+	Type    string   `json:"type"`
+	Chain   []string `json:"chain,omitempty"`
+}
+
+// logging.ErrorField.String, return the message of the error, for fmt.
+func (field ErrorField) String() string {
+	return field.Message
+}
+
+// newErrorField, return the ErrorField of err.
+func newErrorField(err error) ErrorField {
+	field := ErrorField{Message: err.Error(), Type: reflect.TypeOf(err).String()}
+	var unwrap func(err error)
+	unwrap = func(err error) {
+		switch wrapper := err.(type) {
+		case interface{ Unwrap() error }:
+			if wrapped := wrapper.Unwrap(); wrapped != nil {
+				field.Chain = append(field.Chain, wrapped.Error())
+				unwrap(wrapped)
+			}
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range wrapper.Unwrap() {
+				if wrapped != nil {
+					field.Chain = append(field.Chain, wrapped.Error())
+					unwrap(wrapped)
+				}
+			}
+		}
+	}
+	unwrap(err)
+	return field
+}
+
+// logging.MessageRecord, a record of a Logger. The records of the loggers come from a pool
+// and are cleared and reused once every handler returned, so Logger.Record is valid only
+// during a call of a filter, a hook, a processor or a handler, which must copy the record,
+// like AsyncHandler does, to keep it. SetRecordPool(false) makes a record per call instead.
 type MessageRecord struct {
 	Level         MessageLevel
 	LevelString   string
@@ -15,31 +134,211 @@ type MessageRecord struct {
 	Pid           int
 	Program       string
 	Time          string
+	Created       time.Time
 	FuncName      string
 	LongFileName  string
 	ShortFileName string
 	Line          int
 	Color         string
 	ColorClear    string
+	Fields        Fields
+	Stack         string        // stack trace of the caller, see Logger.CaptureStack.
+	Errors        []string      // messages of the error arguments and of the errors they wrap, outermost first.
+	LoggerName    string        // name of the logger, see GetLogger.
+	GoroutineID   uint64        // id of the goroutine of the caller, 0 unless Logger.GoroutineID is set.
+	Elapsed       time.Duration // time since the start or the previous record, 0 unless Logger.Elapsed is set.
+
+	// Context of Logger.WithContext, nil otherwise, for handlers correlating records with
+	// what it carries, like a trace.
+	Context context.Context
+
+	summary bool // a summary of DedupFilter, it isn't deduplicated.
+}
+
+// logging.MessageRecord.PID, return Pid, for formats like {{.PID}}.
+func (record MessageRecord) PID() int {
+	return record.Pid
+}
+
+// logging.MessageRecord.FullFileName, return LongFileName, the complete path of the file of
+// the caller, for formats like {{.FullFileName}}.
+func (record MessageRecord) FullFileName() string {
+	return record.LongFileName
+}
+
+// logging.MessageRecord.PackagePath, return the import path of the package of the caller,
+// like "github.com/gamelife1314/logging/examples", from FuncName.
+func (record MessageRecord) PackagePath() string {
+	name := record.FuncName
+	slash := strings.LastIndexByte(name, '/') + 1
+	if dot := strings.IndexByte(name[slash:], '.'); dot >= 0 {
+		return name[:slash+dot]
+	}
+	return name
+}
+
+// logging.MessageRecord.SetLevel, change the level of record, with its LevelString and Color, like in a Processor.
+func (record *MessageRecord) SetLevel(level MessageLevel) {
+	record.Level = level
+	record.LevelString, record.Color = levelInfo(level)
+}
+
+// logging.MessageRecord.SetField, set the field key of record to value in a copy of Fields,
+// which may be shared with the logger and other records, like in a Processor.
+func (record *MessageRecord) SetField(key string, value interface{}) {
+	fields := make(Fields, len(record.Fields)+1)
+	for k, v := range record.Fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	record.Fields = fields
+}
+
+// goroutineID, return the id of the current goroutine, parsed from the header of its stack
+// trace, like "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buffer [64]byte
+	header := buffer[:runtime.Stack(buffer[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	var id uint64
+	for _, c := range header {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
 }
 
 // logging.getMessageRecord, make a record and return it's reference.
+// Without arguments format is the message itself, it's not parsed.
 func GetMessageRecord(level MessageLevel, format string, a ...interface{}) *MessageRecord {
-	message := fmt.Sprintf(format, a...)
-	pc, file, line, _ := runtime.Caller(3)
-	record := &MessageRecord{
+	return newMessageRecord(0, false, level, sprintf(len(a) > 0, format, a...))
+}
+
+// errorChain, return the messages of every error in a and of the errors they wrap, nil if there's none.
+func errorChain(a []interface{}) []string {
+	var chain []string
+	for _, arg := range a {
+		err, ok := arg.(error)
+		for ok && err != nil {
+			chain = append(chain, err.Error())
+			err = errors.Unwrap(err)
+		}
+	}
+	return chain
+}
+
+// sprintf, return format parsed with a if parse is set, or format itself.
+func sprintf(parse bool, format string, a ...interface{}) string {
+	if parse {
+		return fmt.Sprintf(format, a...)
+	}
+	return format
+}
+
+// newMessageRecord, make a record whose caller is skip frames above the first caller outside
+// package logging, with the stack trace from the caller if stack is set.
+func newMessageRecord(skip int, stack bool, level MessageLevel, message string) *MessageRecord {
+	frame := callerFrame(skip)
+	levelString, color := levelInfo(level)
+	record := recordPool.Get().(*MessageRecord)
+	*record = MessageRecord{
 		Level:         level,
 		Message:       message,
 		Pid:           os.Getpid(),
 		Program:       filepath.Base(os.Args[0]),
 		Time:          "",
-		FuncName:      runtime.FuncForPC(pc).Name(),
-		LongFileName:  file,
-		ShortFileName: filepath.Base(file),
-		Line:          line,
-		Color:         LevelColorFlag[level],
+		Created:       time.Now(),
+		FuncName:      frame.Function,
+		LongFileName:  frame.File,
+		ShortFileName: filepath.Base(frame.File),
+		Line:          frame.Line,
+		Color:         color,
 		ColorClear:    LevelColorSeqClear,
-		LevelString:   LevelString[level],
+		LevelString:   levelString,
+	}
+	if stack {
+		record.Stack = callerStack(skip)
 	}
 	return record
 }
+
+// recordPool, records of Logger.output, reused once they're handled.
+var recordPool = sync.Pool{
+	New: func() interface{} {
+		return new(MessageRecord)
+	},
+}
+
+// noRecordPool, set by SetRecordPool(false).
+var noRecordPool atomic.Bool
+
+// logging.SetRecordPool, set whether the records of the loggers are reused once they're handled,
+// disable it for hooks, processors or handlers keeping Logger.Record after their call, which
+// read a cleared or another record otherwise, at the cost of an allocation per record.
+// Default: true
+func SetRecordPool(enabled bool) {
+	noRecordPool.Store(!enabled)
+}
+
+// releaseRecord, clear record and put it back to the pool, unless SetRecordPool disabled it.
+func releaseRecord(record *MessageRecord) {
+	if noRecordPool.Load() {
+		return
+	}
+	*record = MessageRecord{}
+	recordPool.Put(record)
+}
+
+// packagePrefix, prefix of the function names of package logging, like "github.com/gamelife1314/logging.".
+var packagePrefix = reflect.TypeOf(MessageRecord{}).PkgPath() + "."
+
+// callerFrame, return the first frame outside package logging, so the caller is
+// correct whichever method of Logger is called, then skip more frames above it.
+// Frames of test files count as outside.
+func callerFrame(skip int) runtime.Frame {
+	var caller runtime.Frame
+	walkCallers(skip, func(frame runtime.Frame) bool {
+		caller = frame
+		return false
+	})
+	return caller
+}
+
+// callerStack, return the stack trace from the caller of callerFrame, a function and its file:line per frame.
+func callerStack(skip int) string {
+	buffer := new(bytes.Buffer)
+	walkCallers(skip, func(frame runtime.Frame) bool {
+		fmt.Fprintf(buffer, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		return true
+	})
+	return buffer.String()
+}
+
+// walkCallers, pass the caller of callerFrame and the frames above it to visit until it returns false.
+func walkCallers(skip int, visit func(frame runtime.Frame) bool) {
+	pcs := make([]uintptr, 64+skip)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	outside := false
+	for {
+		frame, more := frames.Next()
+		if !outside {
+			outside = !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go")
+		}
+		if outside {
+			if skip > 0 {
+				skip--
+			} else if !visit(frame) {
+				return
+			}
+		}
+		if !more {
+			if !outside {
+				visit(frame)
+			}
+			return
+		}
+	}
+}
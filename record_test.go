@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordSink, keep the records of the benchmarks on the heap, like a logger does.
+var recordSink *MessageRecord
+
+// BenchmarkRecordPooled and BenchmarkRecordUnpooled, compare the allocations of the records of
+// the pool with a new record per call, like before the pool, the other costs are the same.
+func BenchmarkRecordPooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		recordSink = newMessageRecord(0, false, INFO, "hello world")
+		releaseRecord(recordSink)
+	}
+}
+
+func BenchmarkRecordUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		record := newMessageRecord(0, false, INFO, "hello world")
+		recordSink = new(MessageRecord)
+		*recordSink = *record
+		releaseRecord(record)
+	}
+}
+
+func TestWithErrorFlatFormatters(t *testing.T) {
+	logger, buffer := NewTestLogger()
+	err := fmt.Errorf("wrap: %w", errors.New("boom"))
+	for _, test := range []struct {
+		formatter Formatter
+		want      []string
+	}{
+		{&MessageFormatter{Format: "{{.Message}}", SortFields: true}, []string{"error=wrap: boom", "error_type=*fmt.wrapError", "error_chain=boom"}},
+		{&LogfmtFormatter{SortFields: true}, []string{`error="wrap: boom"`, "error_chain=boom", "error_type=*fmt.wrapError"}},
+		{&GELFFormatter{}, []string{`"_error":"wrap: boom"`, `"_error_type":"*fmt.wrapError"`, `"_error_chain":"boom"`}},
+	} {
+		buffer.Reset()
+		logger.StreamHandler.Formatter = test.formatter
+		logger.WithError(err).Error("failed")
+		for _, want := range test.want {
+			if !strings.Contains(buffer.String(), want) {
+				t.Errorf("%T wrote %q, want %s", test.formatter, buffer.String(), want)
+			}
+		}
+	}
+}
+
+func TestSetRecordPool(t *testing.T) {
+	defer SetRecordPool(true)
+	logger := GetDiscardLogger()
+	var kept []*MessageRecord
+	logger.Hooks = []MessageHook{func(logger *Logger) { kept = append(kept, logger.Record) }}
+
+	SetRecordPool(false)
+	logger.Info("first")
+	logger.Info("second")
+	if kept[0].Message != "first" || kept[1].Message != "second" {
+		t.Fatalf("kept records %q and %q, want first and second", kept[0].Message, kept[1].Message)
+	}
+
+	SetRecordPool(true)
+	logger.Info("pooled")
+	if kept[2].Message != "" {
+		t.Fatalf("pooled record %q kept after the call, want it cleared", kept[2].Message)
+	}
+}
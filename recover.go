@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Logger.RecoverAndLog, recover a panic, log it at CRITICAL with the stack trace from the
+// function which panicked, flush every handler and panic again, so the crash is recorded
+// even by async or buffered handlers. Defer it directly:
+//
+//	defer logger.RecoverAndLog()
+func (l *Logger) RecoverAndLog() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+		panic(r)
+	}
+}
+
+// Logger.RecoverAndSwallow, like RecoverAndLog, but don't panic again, the deferring
+// function returns normally, like a worker goroutine which shouldn't crash the process.
+func (l *Logger) RecoverAndSwallow() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+	}
+}
+
+// Logger.logPanic, log r at CRITICAL with the stack trace from the function which panicked, then flush.
+func (l *Logger) logPanic(r interface{}) {
+	l.mutex.Lock()
+	if CRITICAL >= l.effectiveLevel() {
+		record := newMessageRecord(panicSkip(), true, CRITICAL, fmt.Sprintf("panic: %v", r))
+		record.Errors = errorChain([]interface{}{r})
+		record.Fields = l.Fields
+		if l.Clock != nil {
+			record.Created = l.Clock.Now()
+		}
+		l.dispatch(record)
+	}
+	l.unlock()
+
+	if err := l.Flush(); err != nil {
+		l.reportError(err)
+	}
+}
+
+// panicSkip, return how many frames of the runtime are above the first frame outside
+// package logging, like runtime.gopanic, so the caller is the function which panicked.
+func panicSkip() int {
+	skip := 0
+	walkCallers(0, func(frame runtime.Frame) bool {
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return false
+		}
+		skip++
+		return true
+	})
+	return skip
+}
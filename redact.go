@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"regexp"
+)
+
+// logging.Redactor, mask sensitive data of records before any handler writes
+// them: the substrings of the message and of the error messages matching
+// Patterns, and the values of the fields named in Keys. Add its Hook method to
+// the logger hooks, so it applies to every handler:
+//
+//	redactor := &logging.Redactor{
+//	    Patterns: []*regexp.Regexp{regexp.MustCompile(`password=\S+`)},
+//	    Keys:     []string{"token"},
+//	}
+//	logger.Hooks = append(logger.Hooks, redactor.Hook)
+type Redactor struct {
+	Patterns []*regexp.Regexp
+	Keys     []string
+	Mask     string // Default: "***"
+}
+
+// logging.Redactor.Hook, mask the record of logger, a MessageHook.
+func (redactor *Redactor) Hook(logger *Logger) {
+	mask := redactor.Mask
+	if mask == "" {
+		mask = "***"
+	}
+	record := logger.Record
+	record.Message = redactor.redact(record.Message, mask)
+	if len(record.Errors) > 0 {
+		errs := make([]string, len(record.Errors))
+		for i, err := range record.Errors {
+			errs[i] = redactor.redact(err, mask)
+		}
+		record.Errors = errs
+	}
+	for _, key := range redactor.Keys {
+		if _, ok := record.Fields[key]; ok {
+			// the fields are shared with the logger, copy them before masking.
+			fields := make(Fields, len(record.Fields))
+			for k, v := range record.Fields {
+				fields[k] = v
+			}
+			for _, key := range redactor.Keys {
+				if _, ok := fields[key]; ok {
+					fields[key] = mask
+				}
+			}
+			record.Fields = fields
+			break
+		}
+	}
+}
+
+func (redactor *Redactor) redact(s, mask string) string {
+	for _, pattern := range redactor.Patterns {
+		s = pattern.ReplaceAllLiteralString(s, mask)
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	registryMutex sync.Mutex
+	registry      = map[string]*Logger{}
+)
+
+// logging.GetLogger, return the logger named name, creating it and its missing
+// ancestors first. Names are dotted, "app" is the parent of "app.db", and the
+// root logger, named "", is the ancestor of all of them; it's Default(), so
+// GetLogger("") and the package functions share a logger, and after SetDefault
+// the new default logger is the root. A new logger has level NOTSET, so it inherits the level
+// of the nearest ancestor which has one, no handlers, and Propagate set, so
+// its records are written by the handlers of its ancestors up to the first one
+// which doesn't propagate. Set Level, handlers or Propagate to tune a subsystem.
+func GetLogger(name string) *Logger {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	return getLogger(name)
+}
+
+// getLogger, GetLogger with registryMutex held.
+func getLogger(name string) *Logger {
+	if name == "" {
+		return Default()
+	}
+	if logger, ok := registry[name]; ok {
+		return logger
+	}
+	logger := &Logger{Name: name, Propagate: true}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		logger.parent = getLogger(name[:i])
+	} else {
+		// the parent is looked up per record, see Logger.ancestor.
+		logger.topLevel = true
+	}
+	registry[name] = logger
+	return logger
+}
@@ -0,0 +1,30 @@
+package logging
+
+import "testing"
+
+func TestGetLoggerRootIsDefault(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+
+	if GetLogger("") != Default() {
+		t.Fatal(`GetLogger("") isn't Default()`)
+	}
+	logger, buffer := NewTestLogger()
+	SetDefault(logger)
+	if GetLogger("") != logger {
+		t.Fatal(`GetLogger("") isn't the logger of SetDefault`)
+	}
+
+	child := GetLogger("registry.test")
+	child.Clock = logger.Clock
+	child.Info("propagated")
+	Info("direct")
+	want := "2000-01-01T00:00:00Z INFO propagated\n2000-01-01T00:00:00Z INFO direct\n"
+	if buffer.String() != want {
+		t.Fatalf("output %q, want %q", buffer.String(), want)
+	}
+
+	// a logger of GetLogger as the default one has no parent, instead of being its own ancestor.
+	SetDefault(GetLogger("registry"))
+	withTimeout(t, func() { child.Info("cycle") })
+}
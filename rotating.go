@@ -0,0 +1,481 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logging.RotatingFileHandler, write to FileName and rotate it when it would
+// exceed MaxBytes, the backups are named FileName.1, FileName.2, ... up to
+// FileName.BackupCount, the oldest one is deleted. Rotation never occurs if
+// either MaxBytes or BackupCount is zero.
+type RotatingFileHandler struct {
+	Level       MessageLevel
+	Filter      MessageFilter
+	Formatter   Formatter
+	FileName    string
+	MaxBytes    int64
+	BackupCount int
+
+	// Compress gzips every backup to FileName.N.gz on a background goroutine,
+	// the active file stays uncompressed. A backup which fails to compress is kept as is.
+	// Writes never wait for it, a backup shifted meanwhile is compressed under its new name.
+	Compress bool
+
+	mutex       sync.Mutex
+	file        *os.File
+	size        int64
+	compressing sync.WaitGroup
+}
+
+// logging.RotatingFileHandler.Handle, write the record of logger if it passes level and filter.
+func (handler *RotatingFileHandler) Handle(logger *Logger) error {
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, handler.Write)
+}
+
+// logging.RotatingFileHandler.SetLevel, set the minimum level of records to write.
+func (handler *RotatingFileHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.RotatingFileHandler.Write, write message to FileName, rotate it first if needed.
+func (handler *RotatingFileHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.file == nil {
+		if err := handler.open(); err != nil {
+			return err
+		}
+	}
+	if handler.shouldRotate(len(message)) {
+		if err := handler.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := handler.file.Write(message)
+	handler.size += int64(n)
+	return err
+}
+
+// logging.RotatingFileHandler.Close, close the current file and wait for the backups being compressed.
+func (handler *RotatingFileHandler) Close() error {
+	handler.mutex.Lock()
+	var err error
+	if handler.file != nil {
+		err = handler.file.Close()
+		handler.file = nil
+	}
+	handler.mutex.Unlock()
+
+	// compressions take the lock to name their gzip.
+	handler.compressing.Wait()
+	return err
+}
+
+// logging.RotatingFileHandler.Reopen, close the current file, the next write opens FileName again.
+func (handler *RotatingFileHandler) Reopen() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.file == nil {
+		return nil
+	}
+	err := handler.file.Close()
+	handler.file = nil
+	return err
+}
+
+func (handler *RotatingFileHandler) shouldRotate(n int) bool {
+	if handler.MaxBytes <= 0 || handler.BackupCount <= 0 {
+		return false
+	}
+	return handler.size > 0 && handler.size+int64(n) > handler.MaxBytes
+}
+
+// open, open FileName in append mode and remember its size.
+func (handler *RotatingFileHandler) open() error {
+	file, info, err := openLogFile(handler.FileName)
+	if err != nil {
+		return err
+	}
+	handler.file = file
+	handler.size = info.Size()
+	return nil
+}
+
+// rotate, shift every backup one place, compressed or not, move FileName to FileName.1 and reopen it.
+func (handler *RotatingFileHandler) rotate() error {
+	if err := handler.file.Close(); err != nil {
+		return err
+	}
+	handler.file = nil
+	oldest := backupName(handler.FileName, handler.BackupCount)
+	os.Remove(oldest)
+	os.Remove(oldest + ".gz")
+	for i := handler.BackupCount - 1; i > 0; i-- {
+		from, to := backupName(handler.FileName, i), backupName(handler.FileName, i+1)
+		os.Rename(from, to)
+		os.Rename(from+".gz", to+".gz")
+	}
+	backup := backupName(handler.FileName, 1)
+	if err := os.Rename(handler.FileName, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if handler.Compress {
+		compressBackground(&handler.compressing, &handler.mutex, backup, handler.findBackup)
+	}
+	return handler.open()
+}
+
+// findBackup, return the name of the backup which is the file of info, after the rotations since
+// it was FileName.1, "" if it's been deleted. The caller holds the lock of handler.
+func (handler *RotatingFileHandler) findBackup(info os.FileInfo) string {
+	for i := 1; i <= handler.BackupCount; i++ {
+		if name := backupName(handler.FileName, i); sameFile(name, info) {
+			return name
+		}
+	}
+	return ""
+}
+
+func backupName(fileName string, i int) string {
+	return fmt.Sprintf("%s.%d", fileName, i)
+}
+
+// compressBackground, gzip the backup fileName on a goroutine tracked by wg, without holding
+// mutex, the lock of its handler, while it's compressed. It's opened now, rotations may rename
+// or delete it meanwhile, then, under mutex, find returns its current name, "" if it's gone.
+func compressBackground(wg *sync.WaitGroup, mutex *sync.Mutex, fileName string, find func(backup os.FileInfo) string) {
+	src, err := os.Open(fileName)
+	if err != nil {
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		compressFile(mutex, src, find)
+	}()
+}
+
+// compressFile, gzip src to a temporary file and close it, then under mutex name the gzip after
+// the current name of the backup, plus .gz, and remove the backup, or remove the gzip if the
+// backup is gone. On failure the backup is left as is.
+func compressFile(mutex *sync.Mutex, src *os.File, find func(backup os.FileInfo) string) error {
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.CreateTemp(filepath.Dir(src.Name()), filepath.Base(src.Name())+".*.gz.tmp")
+	if err != nil {
+		return err
+	}
+	err = dst.Chmod(logFileMode)
+	if err == nil {
+		writer := gzip.NewWriter(dst)
+		_, err = io.Copy(writer, src)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	// the backup can't be removed while it's open on Windows.
+	src.Close()
+	if err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	backup := find(info)
+	if backup == "" {
+		return os.Remove(dst.Name())
+	}
+	if err := os.Rename(dst.Name(), backup+".gz"); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	return os.Remove(backup)
+}
+
+// sameFile, report whether name is the file of info.
+func sameFile(name string, info os.FileInfo) bool {
+	other, err := os.Stat(name)
+	return err == nil && os.SameFile(other, info)
+}
+
+// openLogFile, open fileName in append mode, create it if missing.
+func openLogFile(fileName string) (*os.File, os.FileInfo, error) {
+	file, err := openFile(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// logging.TimedRotatingFileHandler, write to FileName and roll it over at
+// every boundary of When, the archives are named after the period they cover,
+// like FileName.2024-01-15 for "midnight" and FileName.2024-01-15_13 for "hourly",
+// and FileName.2024-01-15.1, .2, ... when the name of the period is taken.
+// Only the newest BackupCount archives are kept, zero keeps all of them.
+// A FileMessageHandler writes FileName during a period.
+type TimedRotatingFileHandler struct {
+	Level       MessageLevel
+	Filter      MessageFilter
+	Formatter   Formatter
+	FileName    string
+	When        string // "midnight" or "hourly", Default: "midnight"
+	BackupCount int
+
+	// Compress gzips every archive on a background goroutine, like RotatingFileHandler.Compress.
+	Compress bool
+
+	// BufferSize, FlushInterval and FlushEveryN, buffer the messages like the ones of
+	// FileMessageHandler, the buffer is flushed before every rollover.
+	BufferSize    int
+	FlushInterval time.Duration
+	FlushEveryN   int
+
+	mutex       sync.Mutex
+	file        *FileMessageHandler // writes FileName during the current period.
+	periodFrom  time.Time
+	rolloverAt  time.Time
+	compressing sync.WaitGroup
+}
+
+// logging.TimedRotatingFileHandler.Handle, write the record of logger if it passes level and filter.
+func (handler *TimedRotatingFileHandler) Handle(logger *Logger) error {
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, handler.Write)
+}
+
+// logging.TimedRotatingFileHandler.SetLevel, set the minimum level of records to write.
+func (handler *TimedRotatingFileHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.TimedRotatingFileHandler.Write, write message to FileName, roll it over first
+// if a boundary has passed since the last write.
+func (handler *TimedRotatingFileHandler) Write(message []byte) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.file == nil {
+		if err := handler.open(); err != nil {
+			return err
+		}
+	}
+	if !time.Now().Before(handler.rolloverAt) {
+		if err := handler.rotate(); err != nil {
+			return err
+		}
+	}
+	return handler.file.Write(message)
+}
+
+// logging.TimedRotatingFileHandler.Flush, write the buffered messages to FileName.
+func (handler *TimedRotatingFileHandler) Flush() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.file == nil {
+		return nil
+	}
+	return handler.file.Flush()
+}
+
+// logging.TimedRotatingFileHandler.Close, flush and close the current file, then wait for the archives being compressed.
+func (handler *TimedRotatingFileHandler) Close() error {
+	handler.mutex.Lock()
+	err := handler.closeFile()
+	handler.mutex.Unlock()
+
+	// compressions take the lock to name their gzip.
+	handler.compressing.Wait()
+	return err
+}
+
+// logging.TimedRotatingFileHandler.Reopen, flush and close the current file, the next write opens FileName again.
+func (handler *TimedRotatingFileHandler) Reopen() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.closeFile()
+}
+
+func (handler *TimedRotatingFileHandler) closeFile() error {
+	if handler.file == nil {
+		return nil
+	}
+	err := handler.file.Close()
+	handler.file = nil
+	return err
+}
+
+// open, open FileName in append mode, an existing file belongs to the period of its last modification.
+func (handler *TimedRotatingFileHandler) open() error {
+	file, info, err := openLogFile(handler.FileName)
+	if err != nil {
+		return err
+	}
+	handler.file = &FileMessageHandler{
+		Destination:   file,
+		Path:          handler.FileName,
+		BufferSize:    handler.BufferSize,
+		FlushInterval: handler.FlushInterval,
+		FlushEveryN:   handler.FlushEveryN,
+	}
+	handler.periodFrom = handler.periodStart(info.ModTime())
+	handler.rolloverAt = handler.nextPeriod(handler.periodFrom)
+	return nil
+}
+
+// rotate, move FileName to the archive of its period and reopen it. The fresh file starts
+// the current period, so an idle process which skipped several boundaries rolls over only once.
+func (handler *TimedRotatingFileHandler) rotate() error {
+	if err := handler.closeFile(); err != nil {
+		return err
+	}
+	archive := handler.archiveName()
+	if err := os.Rename(handler.FileName, archive); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	handler.removeOldArchives()
+	if handler.Compress {
+		compressBackground(&handler.compressing, &handler.mutex, archive, func(info os.FileInfo) string {
+			if sameFile(archive, info) {
+				return archive
+			}
+			return ""
+		})
+	}
+	return handler.open()
+}
+
+// archiveName, return the name of the archive of the current period, with a counter if it's
+// taken, compressed or not, like after the file of the period was replaced, so it's never
+// overwritten.
+func (handler *TimedRotatingFileHandler) archiveName() string {
+	archive := handler.FileName + "." + handler.periodFrom.Format(handler.suffixLayout())
+	name := archive
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s.%d", archive, i)
+	}
+	return name
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// removeOldArchives, delete archives except the newest BackupCount, compressed or not.
+// Only the names rotate gives are archives, other files next to FileName, like
+// FileName.bak, are left alone.
+func (handler *TimedRotatingFileHandler) removeOldArchives() {
+	if handler.BackupCount <= 0 {
+		return
+	}
+	dir := filepath.Dir(handler.FileName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var archives []timedArchive
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		archive, ok := handler.parseArchive(entry.Name())
+		if ok && !seen[archive.name] {
+			seen[archive.name] = true
+			archive.name = filepath.Join(dir, archive.name)
+			archives = append(archives, archive)
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		if !archives[i].period.Equal(archives[j].period) {
+			return archives[i].period.Before(archives[j].period)
+		}
+		return archives[i].n < archives[j].n
+	})
+	for len(archives) > handler.BackupCount {
+		os.Remove(archives[0].name)
+		os.Remove(archives[0].name + ".gz")
+		archives = archives[1:]
+	}
+}
+
+// timedArchive, an archive of TimedRotatingFileHandler, named without .gz, of period and counter n.
+type timedArchive struct {
+	name   string
+	period time.Time
+	n      int
+}
+
+// parseArchive, return the archive named name, which is FileName followed by a period of the
+// suffix layout, a counter if the period was taken, and .gz once compressed.
+func (handler *TimedRotatingFileHandler) parseArchive(name string) (timedArchive, bool) {
+	suffix, ok := strings.CutPrefix(name, filepath.Base(handler.FileName)+".")
+	if !ok {
+		return timedArchive{}, false
+	}
+	name = strings.TrimSuffix(name, ".gz")
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	archive := timedArchive{name: name}
+	if i := strings.LastIndexByte(suffix, '.'); i >= 0 {
+		n, err := strconv.Atoi(suffix[i+1:])
+		if err != nil || n <= 0 {
+			return timedArchive{}, false
+		}
+		suffix, archive.n = suffix[:i], n
+	}
+	period, err := time.Parse(handler.suffixLayout(), suffix)
+	if err != nil {
+		return timedArchive{}, false
+	}
+	archive.period = period
+	return archive, true
+}
+
+func (handler *TimedRotatingFileHandler) hourly() bool {
+	return handler.When == "hourly"
+}
+
+func (handler *TimedRotatingFileHandler) suffixLayout() string {
+	if handler.hourly() {
+		return "2006-01-02_15"
+	}
+	return "2006-01-02"
+}
+
+// periodStart, return the beginning of the period t belongs to, in local time.
+func (handler *TimedRotatingFileHandler) periodStart(t time.Time) time.Time {
+	t = t.Local()
+	if handler.hourly() {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod, return the beginning of the period following the one starting at from.
+func (handler *TimedRotatingFileHandler) nextPeriod(from time.Time) time.Time {
+	if handler.hourly() {
+		return from.Add(time.Hour)
+	}
+	return from.AddDate(0, 0, 1)
+}
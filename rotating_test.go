@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimedRotatingFileHandlerKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	for _, name := range []string{"app.log", "app.log.2020-01-01", "app.log.2020-01-02", "app.log.2020-01-03.gz",
+		"app.log.bak", "app.log.2020-01-04.old", "app.log.1", "other.log.2020-01-01"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the file was last written on 2020-01-05, the next write rolls it over.
+	modified := time.Date(2020, 1, 5, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(fileName, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &TimedRotatingFileHandler{FileName: fileName, BackupCount: 2}
+	defer handler.Close()
+	if err := handler.Write([]byte("today\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"app.log", "app.log.1", "app.log.2020-01-03.gz", "app.log.2020-01-04.old", "app.log.2020-01-05",
+		"app.log.bak", "other.log.2020-01-01"}
+	sort.Strings(want)
+	if len(names) != len(want) {
+		t.Fatalf("files %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("files %q, want %q", names, want)
+		}
+	}
+}
+
+func TestTimedRotatingFileHandlerArchiveTaken(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	for name, content := range map[string]string{"app.log": "current\n", "app.log.2020-01-05": "archived\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	modified := time.Date(2020, 1, 5, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(fileName, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &TimedRotatingFileHandler{FileName: fileName, BackupCount: 5}
+	defer handler.Close()
+	if err := handler.Write([]byte("today\n")); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"app.log.2020-01-05": "archived\n", "app.log.2020-01-05.1": "current\n", "app.log": "today\n"} {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(data) != want {
+			t.Errorf("%s has %q, %v, want %q", name, data, err, want)
+		}
+	}
+}
+
+func TestTimedRotatingFileHandlerBuffered(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	handler := &TimedRotatingFileHandler{FileName: fileName, BufferSize: 4096}
+	defer handler.Close()
+
+	if err := handler.Write([]byte("buffered\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(fileName); len(data) != 0 {
+		t.Fatalf("%q written before Flush", data)
+	}
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(fileName); string(data) != "buffered\n" {
+		t.Fatalf("%q written by Flush, want %q", data, "buffered\n")
+	}
+}
+
+func TestRotatingFileHandlerCompressWhileRotating(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "app.log")
+	handler := &RotatingFileHandler{FileName: fileName, MaxBytes: 10, BackupCount: 3, Compress: true}
+	// every write rotates, the backups are shifted while the previous ones are compressed.
+	for i := 0; i < 50; i++ {
+		if err := handler.Write([]byte(fmt.Sprintf("message %02d\n", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"app.log", "app.log.1.gz", "app.log.2.gz", "app.log.3.gz"}; strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Fatalf("files %q, want %q", names, want)
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		backup := backupName(fileName, i) + ".gz"
+		if backupInfo, err := os.Stat(backup); err != nil || backupInfo.Mode() != info.Mode() {
+			t.Errorf("%s has mode %v, %v, want %v like %s", backup, backupInfo.Mode(), err, info.Mode(), fileName)
+		}
+		file, err := os.Open(backup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(reader)
+		file.Close()
+		if want := fmt.Sprintf("message %02d\n", 49-i); err != nil || string(data) != want {
+			t.Errorf("%s has %q, %v, want %q", backup, data, err, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"sync"
+	"time"
+)
+
+// logging.SamplingFilter, pass the First records of each level and message
+// per Interval, and drop the following identical ones until the interval
+// resets. Assign its Filter method to a Filter field:
+//
+//	sampler := &logging.SamplingFilter{First: 10, Interval: time.Second}
+//	logger.Filter = sampler.Filter
+type SamplingFilter struct {
+	First    int
+	Interval time.Duration
+
+	mutex       sync.Mutex
+	windowStart time.Time
+	counts      map[samplingKey]int
+	passed      uint64
+	suppressed  uint64
+}
+
+type samplingKey struct {
+	level   MessageLevel
+	message string
+}
+
+// logging.SamplingFilter.Filter, report whether the record of logger is within the First of its interval.
+func (filter *SamplingFilter) Filter(logger *Logger) bool {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	now := time.Now()
+	if filter.counts == nil || now.Sub(filter.windowStart) >= filter.Interval {
+		filter.counts = make(map[samplingKey]int)
+		filter.windowStart = now
+	}
+	key := samplingKey{level: logger.Record.Level, message: logger.Record.Message}
+	filter.counts[key]++
+	if filter.counts[key] > filter.First {
+		filter.suppressed++
+		return false
+	}
+	filter.passed++
+	return true
+}
+
+// logging.SamplingFilter.Passed, return how many records have passed.
+func (filter *SamplingFilter) Passed() uint64 {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	return filter.passed
+}
+
+// logging.SamplingFilter.Suppressed, return how many records have been dropped.
+func (filter *SamplingFilter) Suppressed() uint64 {
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	return filter.suppressed
+}
@@ -0,0 +1,31 @@
+//go:build !plan9
+// +build !plan9
+
+package logging
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Logger.HandleSIGHUP, call Reopen whenever the process receives SIGHUP, so an external tool
+// like logrotate can move the files and signal the process to write to fresh ones. Calling it
+// again does nothing, errors go to ErrorHandler.
+//
+// RotatingFileHandler and TimedRotatingFileHandler are reopened too, so they follow external
+// rotation, but they rotate on their own as well, don't let both rotate the same file.
+// SIGHUP is never received on Windows.
+func (l *Logger) HandleSIGHUP() {
+	l.sighup.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		go func() {
+			for range signals {
+				if err := l.Reopen(); err != nil {
+					l.reportError(err)
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,7 @@
+//go:build plan9
+// +build plan9
+
+package logging
+
+// Logger.HandleSIGHUP, do nothing, there's no SIGHUP on Plan 9.
+func (l *Logger) HandleSIGHUP() {}
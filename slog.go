@@ -0,0 +1,153 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+)
+
+// logging.SlogHandler, a slog.Handler passing the records of log/slog to Logger, or to the
+// default logger if it's nil, so its handlers and formatters apply:
+//
+//	slog.SetDefault(slog.New(&logging.SlogHandler{Logger: logger}))
+//
+// Attributes become fields, the ones in groups are named after them, like "request.id".
+type SlogHandler struct {
+	Logger *Logger
+
+	fields Fields // attributes of WithAttrs, already qualified.
+	group  string // prefix of the attributes, like "request.".
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// logging.SlogHandler.Enabled, report whether records of level pass the logger level.
+func (handler *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.logger().IsEnabled(slogLevel(level))
+}
+
+// logging.SlogHandler.Handle, log record with the caller it was made by.
+func (handler *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	l := handler.logger()
+	l.mutex.Lock()
+	defer l.unlock()
+
+	level := slogLevel(record.Level)
+	if level < l.effectiveLevel() {
+		l.counters().level.Add(1)
+		return nil
+	}
+	stack := l.CaptureStack > NOTSET && level >= l.CaptureStack
+	message := newMessageRecord(0, stack, level, record.Message)
+	if !record.Time.IsZero() {
+		message.Created = record.Time
+	}
+	if ctx != nil {
+		message.Context = ctx
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		message.FuncName = frame.Function
+		message.LongFileName = frame.File
+		message.ShortFileName = filepath.Base(frame.File)
+		message.Line = frame.Line
+	}
+
+	fields := make(Fields, len(l.Fields)+len(handler.fields)+record.NumAttrs())
+	for key, value := range l.Fields {
+		fields[key] = value
+	}
+	for key, value := range handler.fields {
+		fields[key] = value
+	}
+	var values []interface{}
+	record.Attrs(func(attr slog.Attr) bool {
+		values = addSlogAttr(fields, handler.group, attr, values)
+		return true
+	})
+	if len(fields) > 0 {
+		message.Fields = fields
+	}
+	message.Errors = errorChain(values)
+
+	l.dispatch(message)
+	return nil
+}
+
+// logging.SlogHandler.WithAttrs, return a handler adding attrs to every record.
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return handler
+	}
+	child := *handler
+	child.fields = make(Fields, len(handler.fields)+len(attrs))
+	for key, value := range handler.fields {
+		child.fields[key] = value
+	}
+	for _, attr := range attrs {
+		addSlogAttr(child.fields, handler.group, attr, nil)
+	}
+	return &child
+}
+
+// logging.SlogHandler.WithGroup, return a handler qualifying the following attributes with name.
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	child := *handler
+	child.group = handler.group + name + "."
+	return &child
+}
+
+func (handler *SlogHandler) logger() *Logger {
+	if handler.Logger != nil {
+		return handler.Logger
+	}
+	return Default()
+}
+
+// addSlogAttr, add attr to fields with its key prefixed by group, groups are flattened,
+// and return values with the value of attr appended.
+func addSlogAttr(fields Fields, group string, attr slog.Attr, values []interface{}) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return values
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			group += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			values = addSlogAttr(fields, group, member, values)
+		}
+		return values
+	}
+	value := attr.Value.Any()
+	fields[group+attr.Key] = value
+	return append(values, value)
+}
+
+// slogLevel, map a slog level to the nearest MessageLevel at or below it, the levels
+// between INFO and WARN map to NOTICE from slog.LevelInfo+2, above ERROR to CRITICAL
+// from slog.LevelError+4.
+func slogLevel(level slog.Level) MessageLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelInfo+2:
+		return INFO
+	case level < slog.LevelWarn:
+		return NOTICE
+	case level < slog.LevelError:
+		return WARNING
+	case level < slog.LevelError+4:
+		return ERROR
+	default:
+		return CRITICAL
+	}
+}
@@ -0,0 +1,168 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+	"sync"
+	"time"
+)
+
+// logging.SyslogHandler, write records to the syslog daemon, the record level is
+// mapped to the syslog severity, like CRITICAL to LOG_CRIT and ERROR to LOG_ERR.
+type SyslogHandler struct {
+	Level     MessageLevel
+	Filter    MessageFilter
+	Formatter Formatter
+
+	// Network and Address of a remote syslog daemon, like "udp" and "logs.example.com:514".
+	// Leave both empty to connect to the local syslog daemon.
+	Network string
+	Address string
+
+	// Facility, Default: syslog.LOG_USER
+	Facility syslog.Priority
+
+	// Tag of every message, Default: the program name
+	Tag string
+
+	// WriteTimeout, abandon a message which isn't written in time, connecting included, the
+	// error wraps context.DeadlineExceeded and os.ErrDeadlineExceeded. The abandoned write
+	// finishes on its own goroutine, then its connection is closed, the next message
+	// reconnects. Default: 0, no limit
+	WriteTimeout time.Duration
+
+	// MaxMessageSize, over udp, the longest message without the syslog header, a longer one is
+	// truncated and marked like "...(truncated, 4096 bytes)", and reported to the ErrorHandler
+	// of the logger, so it isn't dropped silently. Default: 1024 bytes, like RFC 3164
+	MaxMessageSize int
+
+	mutex  sync.Mutex
+	writer *syslog.Writer
+}
+
+// logging.SyslogHandler.Handle, write the record of logger if it passes level and filter.
+func (handler *SyslogHandler) Handle(logger *Logger) error {
+	level := logger.Record.Level
+	return handle(logger, &handler.Level, handler.Filter, handler.Formatter, func(message []byte) error {
+		if max := handler.maxMessageSize(); max > 0 && len(message) > max {
+			logger.handleError(fmt.Errorf("syslog message of %d bytes truncated to %d", len(message), max))
+		}
+		return handler.write(level, message)
+	})
+}
+
+// logging.SyslogHandler.SetLevel, set the minimum level of records to write.
+func (handler *SyslogHandler) SetLevel(level MessageLevel) {
+	storeLevel(&handler.Level, level)
+}
+
+// logging.SyslogHandler.Write, write message with the INFO severity.
+func (handler *SyslogHandler) Write(message []byte) error {
+	return handler.write(INFO, message)
+}
+
+// logging.SyslogHandler.Close, close the connection to the syslog daemon.
+func (handler *SyslogHandler) Close() error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.writer == nil {
+		return nil
+	}
+	err := handler.writer.Close()
+	handler.writer = nil
+	return err
+}
+
+// write, connect on first use, and drop the connection when a write fails or times out
+// so the next one reconnects.
+func (handler *SyslogHandler) write(level MessageLevel, message []byte) error {
+	message = truncateDatagram(message, handler.maxMessageSize())
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	if handler.WriteTimeout <= 0 {
+		writer, err := handler.dialAndWrite(handler.writer, level, string(message))
+		handler.writer = writer
+		return err
+	}
+
+	type result struct {
+		writer *syslog.Writer
+		err    error
+	}
+	done := make(chan result, 1)
+	go func(writer *syslog.Writer, m string) {
+		writer, err := handler.dialAndWrite(writer, level, m)
+		done <- result{writer, err}
+	}(handler.writer, string(message))
+
+	timer := time.NewTimer(handler.WriteTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		handler.writer = r.writer
+		return r.err
+	case <-timer.C:
+		handler.writer = nil
+		go func() {
+			if r := <-done; r.writer != nil {
+				r.writer.Close()
+			}
+		}()
+		return fmt.Errorf("logging: syslog write: %w", errWriteTimeout)
+	}
+}
+
+// maxMessageSize, return the longest message over udp, 0 for no limit over other networks.
+func (handler *SyslogHandler) maxMessageSize() int {
+	switch handler.Network {
+	case "udp", "udp4", "udp6":
+		if handler.MaxMessageSize > 0 {
+			return handler.MaxMessageSize
+		}
+		return 1024
+	}
+	return 0
+}
+
+// dialAndWrite, write m with the severity of level to writer, connect first if it's nil,
+// return the connection to keep, nil if it failed.
+func (handler *SyslogHandler) dialAndWrite(writer *syslog.Writer, level MessageLevel, m string) (*syslog.Writer, error) {
+	if writer == nil {
+		facility := handler.Facility
+		if facility == 0 {
+			facility = syslog.LOG_USER
+		}
+		var err error
+		writer, err = syslog.Dial(handler.Network, handler.Address, facility|syslog.LOG_INFO, handler.Tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	switch {
+	case level >= CRITICAL:
+		err = writer.Crit(m)
+	case level >= ERROR:
+		err = writer.Err(m)
+	case level >= WARNING:
+		err = writer.Warning(m)
+	case level >= NOTICE:
+		err = writer.Notice(m)
+	case level >= INFO:
+		err = writer.Info(m)
+	default:
+		err = writer.Debug(m)
+	}
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return writer, nil
+}
@@ -0,0 +1,25 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSyslogHandlerWriteTimeout(t *testing.T) {
+	address, stop := stalledListener(t)
+	defer stop()
+	handler := &SyslogHandler{Network: "tcp", Address: address, WriteTimeout: 200 * time.Millisecond}
+	defer handler.Close()
+
+	start := time.Now()
+	err := handler.Write(stalledMessage)
+	assertTimeout(t, err, time.Since(start), handler.WriteTimeout)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("error %v, want one wrapping os.ErrDeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"errors"
+)
+
+// logging.TeeHandler, pass every record to all of Handlers, each one applies
+// its own Level and Filter. It's a single handler writing to many destinations,
+// for places expecting one, like AsyncHandler.Handler or MemoryHandler.Target.
+type TeeHandler struct {
+	Handlers []MessageHandler
+}
+
+// logging.TeeHandler.Handle, pass the record of logger to every handler, return their errors joined.
+func (handler *TeeHandler) Handle(logger *Logger) error {
+	var errs []error
+	for _, child := range handler.Handlers {
+		errs = append(errs, child.Handle(logger))
+	}
+	return errors.Join(errs...)
+}
+
+// logging.TeeHandler.SetLevel, set the level of every handler which is a LevelSetter.
+func (handler *TeeHandler) SetLevel(level MessageLevel) {
+	for _, child := range handler.Handlers {
+		if setter, ok := child.(LevelSetter); ok {
+			setter.SetLevel(level)
+		}
+	}
+}
+
+// logging.TeeHandler.Flush, flush every handler which is a Flusher.
+func (handler *TeeHandler) Flush() error {
+	var errs []error
+	for _, child := range handler.Handlers {
+		if flusher, ok := child.(Flusher); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// logging.TeeHandler.Reopen, reopen every handler which is a Reopener.
+func (handler *TeeHandler) Reopen() error {
+	var errs []error
+	for _, child := range handler.Handlers {
+		if reopener, ok := child.(Reopener); ok {
+			errs = append(errs, reopener.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// logging.TeeHandler.Close, close or flush every handler.
+func (handler *TeeHandler) Close() error {
+	var errs []error
+	for _, child := range handler.Handlers {
+		errs = append(errs, closeHandler(child))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"io"
+	"os"
+)
+
+// isTerminal, report whether w is a file connected to a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// logging.SelectFormatter, return terminal if destination is a terminal, or other,
+// like a colored MessageFormatter on a console and a JSONFormatter in a file or a pipe:
+//
+//	handler := &logging.StreamMessageHandler{Destination: os.Stdout}
+//	handler.Formatter = logging.SelectFormatter(handler.Destination, colored, &logging.JSONFormatter{})
+func SelectFormatter(destination io.Writer, terminal, other Formatter) Formatter {
+	if isTerminal(destination) {
+		return terminal
+	}
+	return other
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package logging
+
+import (
+	"io"
+)
+
+// enableColor, terminals render the escape sequences of LevelColorFlag already.
+func enableColor(w io.Writer) bool {
+	return true
+}
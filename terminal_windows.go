@@ -0,0 +1,47 @@
+//go:build windows
+// +build windows
+
+package logging
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+
+	// consoleColor, remembers per console handle whether escape sequences are enabled.
+	consoleColor sync.Map
+)
+
+// enableColor, turn on virtual terminal processing of the console w, so the
+// escape sequences of LevelColorFlag are rendered. It reports false if the
+// console can't render them, then colors are left out.
+func enableColor(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	handle := syscall.Handle(file.Fd())
+	if enabled, ok := consoleColor.Load(handle); ok {
+		return enabled.(bool)
+	}
+	var mode uint32
+	enabled := false
+	if syscall.GetConsoleMode(handle, &mode) == nil {
+		if mode&enableVirtualTerminalProcessing != 0 {
+			enabled = true
+		} else {
+			r, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+			enabled = r != 0
+		}
+	}
+	consoleColor.Store(handle, enabled)
+	return enabled
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"bytes"
+	"time"
+)
+
+// testTime, the time of every record of NewTestLogger.
+var testTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// logging.NewTestLogger, return a logger of level DEBUG writing to the returned buffer, for tests
+// asserting on the output of code which logs. Every record is dated 2000-01-01T00:00:00Z, so a
+// message looks like, with fields sorted by key:
+//
+//	2000-01-01T00:00:00Z INFO hello world key=value
+func NewTestLogger() (*Logger, *bytes.Buffer) {
+	buffer := new(bytes.Buffer)
+	logger := &Logger{
+		Level: DEBUG,
+		StreamHandler: &StreamMessageHandler{
+			Level:       DEBUG,
+			Formatter:   &MessageFormatter{Format: "{{.Time}} {{.LevelString}} {{.Message}}\n", TimeFormat: time.RFC3339, Location: time.UTC, SortFields: true},
+			Destination: buffer,
+			NoColor:     true,
+		},
+		Clock: FixedClock(testTime),
+	}
+	return logger, buffer
+}
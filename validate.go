@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Logger.Validate, report configurations which are valid but likely mistakes, joined in one
+// error, nil if there's none, like at startup or in a test. Nothing is changed. It reports:
+// a handler whose Level is below the logger level, so its records below it never reach it,
+// no handlers without propagation, so every record is discarded, a nil handler, a
+// StreamMessageHandler or FileMessageHandler without Destination, and a MessageFormatter
+// whose Format is invalid, so DefaultFormat is used instead.
+func (l *Logger) Validate() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	level := l.effectiveLevel()
+	var errs []error
+	handlers := l.handlers()
+	if len(handlers) == 0 && (!l.Propagate || l.ancestor() == nil) {
+		errs = append(errs, errors.New("logging: no handlers, every record is discarded"))
+	}
+	if formatter, ok := l.DefaultFormatter.(*MessageFormatter); ok {
+		if _, err := NewMessageFormatter(formatter.Format, formatter.TimeFormat); err != nil {
+			errs = append(errs, fmt.Errorf("%w of DefaultFormatter, DefaultFormat is used", err))
+		}
+	}
+	for _, handler := range handlers {
+		errs = append(errs, validateHandler(handler, level))
+	}
+	return errors.Join(errs...)
+}
+
+// validateHandler, report the mistakes of handler, and of the handlers it wraps, under a
+// logger of level.
+func validateHandler(handler MessageHandler, level MessageLevel) error {
+	if value := reflect.ValueOf(handler); handler == nil || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return errors.New("logging: nil handler, it panics")
+	}
+	var errs []error
+	switch h := handler.(type) {
+	case *StreamMessageHandler:
+		if h.Destination == nil {
+			errs = append(errs, errors.New("logging: *logging.StreamMessageHandler without Destination, it panics"))
+		}
+	case *FileMessageHandler:
+		if h.Destination == nil {
+			errs = append(errs, errors.New("logging: *logging.FileMessageHandler without Destination, it panics"))
+		}
+	case *AsyncHandler:
+		errs = append(errs, validateHandler(h.Handler, level))
+	case *TeeHandler:
+		for _, child := range h.Handlers {
+			errs = append(errs, validateHandler(child, level))
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(handler))
+	if value.Kind() != reflect.Struct {
+		return errors.Join(errs...)
+	}
+	if field := value.FieldByName("Level"); field.IsValid() && field.Type() == reflect.TypeOf(level) {
+		handlerLevelMutex.RLock()
+		handlerLevel := MessageLevel(field.Int())
+		handlerLevelMutex.RUnlock()
+		if handlerLevel != NOTSET && handlerLevel < level {
+			errs = append(errs, fmt.Errorf("logging: %T has Level %s below the logger level %s, its records below %s never reach it",
+				handler, levelName(handlerLevel), levelName(level), levelName(level)))
+		}
+	}
+	if field := value.FieldByName("Formatter"); field.IsValid() && field.CanInterface() {
+		if formatter, ok := field.Interface().(*MessageFormatter); ok && formatter != nil {
+			if _, err := NewMessageFormatter(formatter.Format, formatter.TimeFormat); err != nil {
+				errs = append(errs, fmt.Errorf("%w of %T, DefaultFormat is used", err, handler))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// levelName, return the name of level, or its number if it has none.
+func levelName(level MessageLevel) string {
+	name, _ := levelInfo(level)
+	if name == "" {
+		return fmt.Sprint(int(level))
+	}
+	return name
+}
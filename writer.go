@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Logger.Writer, return an io.Writer logging every line written to it as a
+// record of level, so it can back log.SetOutput or any library writing to an
+// io.Writer. An unterminated line is kept until the rest of it is written.
+func (l *Logger) Writer(level MessageLevel) io.Writer {
+	return &logWriter{logger: l, level: level}
+}
+
+type logWriter struct {
+	logger *Logger
+	level  MessageLevel
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buffer.Write(p)
+	for {
+		line, err := w.buffer.ReadBytes('\n')
+		if err != nil {
+			// no newline left, keep the partial line for the next write.
+			w.buffer.Write(line)
+			return len(p), nil
+		}
+		w.logger.log(w.level, "%s", bytes.TrimRight(line, "\r\n"))
+	}
+}